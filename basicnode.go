@@ -2,6 +2,11 @@ package rquad
 
 import "image"
 
+// basicNoder is the interface implemented by nodes embedding a BasicNode.
+type basicNoder interface {
+	basicNode() *BasicNode
+}
+
 // BasicNode represents a standard quadtree node.
 //
 // It is a basic implementation of the Node interface, the one used in the
@@ -12,6 +17,8 @@ type BasicNode struct {
 	bounds   image.Rectangle // node bounds
 	color    Color           // node color
 	location Quadrant        // node location inside its parent
+	data     interface{}     // user data
+	index    int             // index of the leaf in the quadtree leaves
 }
 
 // Parent returns the quadtree node that is the parent of current one.
@@ -45,3 +52,24 @@ func (n *BasicNode) Color() Color {
 func (n *BasicNode) Location() Quadrant {
 	return n.location
 }
+
+// Data returns the user data attached to the node, or nil.
+func (n *BasicNode) Data() interface{} {
+	return n.data
+}
+
+// SetData attaches arbitrary user data to the node, such as a cost or a
+// label.
+//
+// Data is kept as long as the node exists, that is it's not affected by
+// operations modifying a quadtree in place, unless the node is removed,
+// when merging its siblings for example. Clone preserves the data of all
+// the nodes, other operations creating new quadtrees don't.
+func (n *BasicNode) SetData(data interface{}) {
+	n.data = data
+}
+
+// basicNode returns n, this method is promoted to types embedding BasicNode.
+func (n *BasicNode) basicNode() *BasicNode {
+	return n
+}
@@ -1,7 +1,6 @@
 package rquad
 
 import (
-	"errors"
 	"image"
 
 	"github.com/arl/imgtools/binimg"
@@ -13,10 +12,10 @@ import (
 // It performs a standard quadtree subdivision of the rectangular area
 // represented by an binimg.Scanner.
 type BasicTree struct {
-	resolution int             // leaf node resolution
-	scanner    imgscan.Scanner // reference image
-	root       Node            // root node
-	leaves     []Node          // leaf nodes (filled during creation)
+	opts    options         // creation options
+	scanner imgscan.Scanner // reference image
+	root    Node            // root node
+	leaves  []Node          // leaf nodes (filled during creation)
 }
 
 // NewBasicTree creates a basic region quadtree from a scannable rectangular
@@ -25,21 +24,15 @@ type BasicTree struct {
 // resolution is the smallest size in pixels that can have a leaf node, no
 // further subdivisions will be performed on a node if its width or height is
 // equal to this value.
+//
+// NewBasicTree is equivalent to calling New with the WithResolution option.
 func NewBasicTree(scanner imgscan.Scanner, resolution int) (*BasicTree, error) {
-	if resolution < 1 {
-		return nil, errors.New("resolution must be greater than 0")
-	}
+	return createBasicTree(scanner, newOptions(WithResolution(resolution)))
+}
 
-	// To ensure a consistent behavior and eliminate corner cases,
-	// the Quadtree's root node needs to have children. Thus, the
-	// first instantiated Node needs to always be subdivided.
-	// This condition asserts the resolution is respected.
-	minDim := scanner.Bounds().Dx()
-	if scanner.Bounds().Dy() < minDim {
-		minDim = scanner.Bounds().Dy()
-	}
-	if minDim < resolution*2 {
-		return nil, errors.New("the image smaller dimension must be greater or equal to twice the resolution")
+func createBasicTree(scanner imgscan.Scanner, o options) (*BasicTree, error) {
+	if err := o.validate(scanner.Bounds()); err != nil {
+		return nil, err
 	}
 
 	// create root node
@@ -50,11 +43,11 @@ func NewBasicTree(scanner imgscan.Scanner, resolution int) (*BasicTree, error) {
 
 	// create quadtree
 	q := &BasicTree{
-		resolution: resolution,
-		scanner:    scanner,
-		root:       root,
+		opts:    o,
+		scanner: scanner,
+		root:    root,
 	}
-	q.subdivide(root)
+	q.subdivide(root, 0)
 	return q, nil
 }
 
@@ -73,7 +66,7 @@ func (q *BasicTree) ForEachLeaf(color Color, fn func(Node)) {
 	}
 }
 
-func (q *BasicTree) newChildNode(bounds image.Rectangle, parent *BasicNode, location Quadrant) *BasicNode {
+func (q *BasicTree) newChildNode(bounds image.Rectangle, parent *BasicNode, location Quadrant, depth int) *BasicNode {
 	n := &BasicNode{
 		color:    Gray,
 		bounds:   bounds,
@@ -91,23 +84,25 @@ func (q *BasicTree) newChildNode(bounds image.Rectangle, parent *BasicNode, loca
 			n.color = Black
 		}
 	case false:
-		// if we reached maximal resolution..
-		if n.bounds.Dx()/2 < q.resolution || n.bounds.Dy()/2 < q.resolution {
+		// if we reached maximal resolution or depth..
+		if !q.opts.canSubdivide(n.bounds, depth) {
 			// ...make this node a black leaf, instead of gray
 			n.color = Black
 		} else {
-			q.subdivide(n)
+			q.subdivide(n, depth)
 		}
 	}
 
 	// fills leaves slices
 	if n.color != Gray {
+		n.index = len(q.leaves)
 		q.leaves = append(q.leaves, n)
 	}
 	return n
 }
 
-func (q *BasicTree) subdivide(n *BasicNode) {
+// subdivide creates the 4 children of n, lying at the given depth.
+func (q *BasicTree) subdivide(n *BasicNode, depth int) {
 	//     x0   x1     x2
 	//  y0 .----.-------.
 	//     |    |       |
@@ -126,10 +121,23 @@ func (q *BasicTree) subdivide(n *BasicNode) {
 	y2 := n.bounds.Max.Y
 
 	// create the 4 children nodes, one per quadrant
-	n.c[Northwest] = q.newChildNode(image.Rect(x0, y0, x1, y1), n, Northwest)
-	n.c[Southwest] = q.newChildNode(image.Rect(x0, y1, x1, y2), n, Southwest)
-	n.c[Northeast] = q.newChildNode(image.Rect(x1, y0, x2, y1), n, Northeast)
-	n.c[Southeast] = q.newChildNode(image.Rect(x1, y1, x2, y2), n, Southeast)
+	n.c[Northwest] = q.newChildNode(image.Rect(x0, y0, x1, y1), n, Northwest, depth+1)
+	n.c[Southwest] = q.newChildNode(image.Rect(x0, y1, x1, y2), n, Southwest, depth+1)
+	n.c[Northeast] = q.newChildNode(image.Rect(x1, y0, x2, y1), n, Northeast, depth+1)
+	n.c[Southeast] = q.newChildNode(image.Rect(x1, y1, x2, y2), n, Southeast, depth+1)
+}
+
+// Compact merges the Gray nodes having 4 leaf children of the same color
+// into a single leaf of that color. Compact is recursive, so that after the
+// call no Gray node has 4 leaf children of the same color.
+//
+// Such nodes may be found when non-uniform nodes are turned into Black leaves
+// because the maximum resolution has been reached, or after modifications of
+// the quadtree.
+func (q *BasicTree) Compact() {
+	compactSubtree(q.root.(basicNoder).basicNode())
+	q.leaves = collectLeaves(q.root, nil)
+	indexLeaves(q.leaves, 0)
 }
 
 // Root returns the quadtree root node.
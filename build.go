@@ -0,0 +1,135 @@
+package rquad
+
+import "image"
+
+// quadrants returns the bounds of the 4 children of a node of bounds b,
+// indexed by Quadrant, following the same subdivision scheme as BasicTree.
+func quadrants(b image.Rectangle) [4]image.Rectangle {
+	x0 := b.Min.X
+	x1 := b.Min.X + b.Dx()/2
+	x2 := b.Max.X
+
+	y0 := b.Min.Y
+	y1 := b.Min.Y + b.Dy()/2
+	y2 := b.Max.Y
+
+	return [4]image.Rectangle{
+		Northwest: image.Rect(x0, y0, x1, y1),
+		Northeast: image.Rect(x1, y0, x2, y1),
+		Southwest: image.Rect(x0, y1, x1, y2),
+		Southeast: image.Rect(x1, y1, x2, y2),
+	}
+}
+
+// subNode returns the child of n at quadrant q or, if n is a leaf, n itself,
+// as a leaf also represents each of its sub-regions.
+//
+// It allows to walk simultaneously 2 quadtrees having different
+// decompositions.
+func subNode(n Node, q Quadrant) Node {
+	if n.Color() != Gray {
+		return n
+	}
+	return n.Child(q)
+}
+
+// implementationOf returns the implementation of q. Quadtrees not created by
+// this package are considered as Basic.
+func implementationOf(q Quadtree) Implementation {
+	if _, ok := q.(*CNTree); ok {
+		return CardinalNeighbour
+	}
+	return Basic
+}
+
+// optionsOf returns the creation options of q, or the default options if q
+// has not been created by this package.
+func optionsOf(q Quadtree) options {
+	switch q := q.(type) {
+	case *BasicTree:
+		return q.opts
+	case *CNTree:
+		return q.opts
+	}
+	return newOptions()
+}
+
+// newTree creates a quadtree of the given implementation, out of an existing
+// hierarchy of basic nodes.
+//
+// It allows operations producing new quadtrees to only deal with basic nodes,
+// whatever the implementation of the quadtree they return.
+func newTree(impl Implementation, root *BasicNode, o options) Quadtree {
+	o.impl = impl
+	if impl == CardinalNeighbour {
+		return newCNTreeFromNodes(root, o)
+	}
+	leaves := collectLeaves(root, nil)
+	indexLeaves(leaves, 0)
+	return &BasicTree{
+		opts:   o,
+		root:   root,
+		leaves: leaves,
+	}
+}
+
+// collectLeaves appends the leaves of the subtree rooted at n to leaves.
+func collectLeaves(n Node, leaves []Node) []Node {
+	if n.Color() != Gray {
+		return append(leaves, n)
+	}
+	for q := Northwest; q <= Southeast; q++ {
+		leaves = collectLeaves(n.Child(q), leaves)
+	}
+	return leaves
+}
+
+// indexLeaves sets the index of the leaves, from position from onwards, to
+// their position in the leaves slice.
+func indexLeaves(leaves []Node, from int) {
+	for i := from; i < len(leaves); i++ {
+		leaves[i].(basicNoder).basicNode().index = i
+	}
+}
+
+// treeDepth returns the depth of the deepest leaf of the subtree rooted at n.
+func treeDepth(n Node) int {
+	if n.Color() != Gray {
+		return 0
+	}
+	depth := 0
+	for q := Northwest; q <= Southeast; q++ {
+		if d := treeDepth(n.Child(q)); d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
+
+// compactNode turns n into a leaf if its 4 children are leaves of the same
+// color.
+func compactNode(n *BasicNode) {
+	col := n.c[Northwest].Color()
+	if col == Gray {
+		return
+	}
+	for _, c := range n.c[1:] {
+		if c.Color() != col {
+			return
+		}
+	}
+	n.color = col
+	n.c = [4]Node{}
+}
+
+// compactSubtree recursively compacts the subtree rooted at n, so that no
+// Gray node has 4 leaf children of the same color.
+func compactSubtree(n *BasicNode) {
+	if n.color != Gray {
+		return
+	}
+	for _, c := range n.c {
+		compactSubtree(c.(basicNoder).basicNode())
+	}
+	compactNode(n)
+}
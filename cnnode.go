@@ -168,3 +168,39 @@ func (n *CNNode) forEachNeighbour(fn func(Node)) {
 	n.forEachNeighbourInDirection(East, fn)
 	n.forEachNeighbourInDirection(South, fn)
 }
+
+// findCardinalNeighbours sets the cardinal neighbours of the leaf n, by
+// looking for them with the generic, bottom-up, neighbour finding technique.
+//
+// It allows to (re)link leaves of a quadtree which decomposition has not been
+// performed by CNTree.subdivide.
+func (n *CNNode) findCardinalNeighbours() {
+	for _, dir := range [...]Side{West, North, East, South} {
+		var cn *CNNode
+		neighbours(n, dir, func(nb Node) {
+			if c := nb.(*CNNode); cn == nil || isMoreCardinal(dir, c, cn) {
+				cn = c
+			}
+		})
+		n.cn[dir] = cn
+	}
+}
+
+// isMoreCardinal reports whether a is a better candidate than b as the
+// cardinal neighbour in direction dir, b and a being both neighbours in that
+// direction.
+func isMoreCardinal(dir Side, a, b *CNNode) bool {
+	switch dir {
+	case West:
+		// top-most
+		return a.bounds.Min.Y < b.bounds.Min.Y
+	case North:
+		// left-most
+		return a.bounds.Min.X < b.bounds.Min.X
+	case East:
+		// bottom-most
+		return a.bounds.Max.Y > b.bounds.Max.Y
+	}
+	// right-most
+	return a.bounds.Max.X > b.bounds.Max.X
+}
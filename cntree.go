@@ -1,7 +1,6 @@
 package rquad
 
 import (
-	"errors"
 	"image"
 	"math"
 
@@ -34,21 +33,19 @@ type CNTree struct {
 //
 // resolution is the minimal dimension of a leaf node, no further subdivisions
 // will be performed on a leaf if its dimension is equal to the resolution.
+//
+// NewCNTree is equivalent to calling New with the WithResolution and
+// WithImplementation(CardinalNeighbour) options.
 func NewCNTree(scanner imgscan.Scanner, resolution int) (*CNTree, error) {
-	if !imgtools.IsPowerOf2Image(scanner) {
-		return nil, errors.New("image must be a square with power-of-2 dimensions")
-	}
+	return createCNTree(scanner, newOptions(WithResolution(resolution)))
+}
 
-	if resolution < 1 {
-		return nil, errors.New("resolution must be greater than 0")
+func createCNTree(scanner imgscan.Scanner, o options) (*CNTree, error) {
+	if !imgtools.IsPowerOf2Image(scanner) {
+		return nil, ErrNotPowerOf2
 	}
-
-	// To ensure a consistent behavior and eliminate corner cases,
-	// the Quadtree's root node needs to have children. Thus, the
-	// first instantiated cnNode needs to always be subdivided.
-	// This condition asserts the resolution is respected.
-	if scanner.Bounds().Dx() < resolution*2 {
-		return nil, errors.New("the image size must be greater or equal to twice the resolution")
+	if err := o.validate(scanner.Bounds()); err != nil {
+		return nil, err
 	}
 
 	// create root node
@@ -63,29 +60,26 @@ func NewCNTree(scanner imgscan.Scanner, resolution int) (*CNTree, error) {
 	// create cardinal neighbour quadtree
 	q := &CNTree{
 		BasicTree: BasicTree{
-			resolution: resolution,
-			scanner:    scanner,
-			root:       root,
+			opts:    o,
+			scanner: scanner,
+			root:    root,
 		},
 		nLevels: 1,
 	}
-	// given the resolution and the size, we can determine
-	// the maxmum number of levels the quadtree can have
-	n := uint(scanner.Bounds().Dx())
-	for n&1 == 0 {
-		n >>= 1
-		if n < uint(q.resolution) {
-			break
-		}
+	// given the resolution, the maximum depth and the size, we can
+	// determine the maximum number of levels the quadtree can have
+	size := scanner.Bounds().Dx()
+	for q.opts.canSubdivide(image.Rect(0, 0, size, size), int(q.nLevels)-1) {
+		size /= 2
 		q.nLevels++
 	}
 
 	// perform the subdivision
-	q.subdivide(q.root.(*CNNode))
+	q.subdivide(q.root.(*CNNode), 0)
 	return q, nil
 }
 
-func (q *CNTree) newNode(bounds image.Rectangle, parent *CNNode, location Quadrant) *CNNode {
+func (q *CNTree) newNode(bounds image.Rectangle, parent *CNNode, location Quadrant, depth int) *CNNode {
 	n := &CNNode{
 		BasicNode: BasicNode{
 			color:    Gray,
@@ -106,8 +100,8 @@ func (q *CNTree) newNode(bounds image.Rectangle, parent *CNNode, location Quadra
 			n.color = Black
 		}
 	case false:
-		// if we reached maximal resolution..
-		if n.size/2 < q.resolution {
+		// if we reached maximal resolution or depth..
+		if !q.opts.canSubdivide(n.bounds, depth) {
 			// ...make this node a black leaf, instead of gray
 			n.color = Black
 		}
@@ -115,12 +109,14 @@ func (q *CNTree) newNode(bounds image.Rectangle, parent *CNNode, location Quadra
 
 	// fills leaves slices
 	if n.color != Gray {
+		n.index = len(q.leaves)
 		q.leaves = append(q.leaves, n)
 	}
 	return n
 }
 
-func (q *CNTree) subdivide(p *CNNode) {
+// subdivide creates the 4 children of p, lying at the given depth.
+func (q *CNTree) subdivide(p *CNNode, depth int) {
 	// Step 1: Decomposing the gray quadrant and updating the
 	//         parent node following the Z-order traversal.
 
@@ -143,10 +139,10 @@ func (q *CNTree) subdivide(p *CNNode) {
 	y2 := p.bounds.Max.Y
 
 	// decompose current node in 4 sub-quadrants
-	nw := q.newNode(image.Rect(x0, y0, x1, y1), p, Northwest)
-	ne := q.newNode(image.Rect(x1, y0, x2, y1), p, Northeast)
-	sw := q.newNode(image.Rect(x0, y1, x1, y2), p, Southwest)
-	se := q.newNode(image.Rect(x1, y1, x2, y2), p, Southeast)
+	nw := q.newNode(image.Rect(x0, y0, x1, y1), p, Northwest, depth+1)
+	ne := q.newNode(image.Rect(x1, y0, x2, y1), p, Northeast, depth+1)
+	sw := q.newNode(image.Rect(x0, y1, x1, y2), p, Southwest, depth+1)
+	se := q.newNode(image.Rect(x1, y1, x2, y2), p, Southeast, depth+1)
 
 	// at creation, each sub-quadrant first inherits its parent external neighbours
 	nw.cn[West] = p.cn[West]   // inherited
@@ -182,19 +178,29 @@ func (q *CNTree) subdivide(p *CNNode) {
 
 	// subdivide non-leaf nodes
 	if nw.color == Gray {
-		q.subdivide(nw)
+		q.subdivide(nw, depth+1)
 	}
 	if ne.color == Gray {
-		q.subdivide(ne)
+		q.subdivide(ne, depth+1)
 	}
 	if sw.color == Gray {
-		q.subdivide(sw)
+		q.subdivide(sw, depth+1)
 	}
 	if se.color == Gray {
-		q.subdivide(se)
+		q.subdivide(se, depth+1)
 	}
 }
 
+// Compact merges the Gray nodes having 4 leaf children of the same color
+// into a single leaf of that color. Compact is recursive, so that after the
+// call no Gray node has 4 leaf children of the same color.
+//
+// The cardinal neighbours of the leaves are updated accordingly.
+func (q *CNTree) Compact() {
+	q.BasicTree.Compact()
+	q.linkNeighbours()
+}
+
 // locate returns the Node that contains the given point, or nil.
 func (q *CNTree) locate(pt image.Point) Node {
 	// binary branching method assumes the point lies in the bounds
@@ -235,3 +241,50 @@ func (q *CNTree) locate(pt image.Point) Node {
 	}
 	return node
 }
+
+// newCNTreeFromNodes creates a cardinal neighbour quadtree out of an existing
+// hierarchy of basic nodes, the root of which must be a square with power-of-2
+// dimensions.
+func newCNTreeFromNodes(root *BasicNode, o options) *CNTree {
+	q := &CNTree{
+		BasicTree: BasicTree{
+			opts: o,
+		},
+		nLevels: uint(treeDepth(root)) + 1,
+	}
+	cnroot := copyCNNode(root)
+	q.root = cnroot
+	q.leaves = collectLeaves(cnroot, nil)
+	indexLeaves(q.leaves, 0)
+	q.linkNeighbours()
+	return q
+}
+
+// copyCNNode returns a copy of the subtree rooted at n, made of CNNode
+// instances. The cardinal neighbours are not set.
+func copyCNNode(n *BasicNode) *CNNode {
+	cn := &CNNode{
+		BasicNode: BasicNode{
+			color:    n.color,
+			bounds:   n.bounds,
+			location: n.location,
+			data:     n.data,
+		},
+		size: n.bounds.Dx(),
+	}
+	if n.color == Gray {
+		for i := range n.c {
+			c := copyCNNode(n.c[i].(*BasicNode))
+			c.parent = cn
+			cn.c[i] = c
+		}
+	}
+	return cn
+}
+
+// linkNeighbours sets the cardinal neighbours of all the leaves.
+func (q *CNTree) linkNeighbours() {
+	for _, n := range q.leaves {
+		n.(*CNNode).findCardinalNeighbours()
+	}
+}
@@ -0,0 +1,44 @@
+package rquad
+
+import (
+	"testing"
+
+	"github.com/arl/go-rquad/internal"
+	"github.com/arl/imgtools/imgscan"
+)
+
+func TestCNTreeLinkNeighbours(t *testing.T) {
+	for _, fn := range []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+		"./testdata/labyrinth4.8x8.png",
+	} {
+		bm, err := internal.LoadPNG(fn)
+		check(t, err)
+		scanner, err := imgscan.NewScanner(bm)
+		check(t, err)
+
+		for _, res := range []int{1, 2, 4} {
+			q, err := NewCNTree(scanner, res)
+			check(t, err)
+
+			// cardinal neighbours set during subdivision
+			want := make(map[*CNNode][4]*CNNode)
+			for _, n := range q.leaves {
+				want[n.(*CNNode)] = n.(*CNNode).cn
+			}
+
+			q.linkNeighbours()
+			for _, n := range q.leaves {
+				cn := n.(*CNNode)
+				for s, nb := range cn.cn {
+					if nb != want[cn][s] {
+						t.Errorf("%s res %d, leaf %v, %v cardinal neighbour: got %v, want %v",
+							fn, res, cn.bounds, Side(s), boundsOf(nb), boundsOf(want[cn][s]))
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package rquad
+
+import (
+	"image"
+
+	"github.com/arl/imgtools/imgscan"
+)
+
+// CoarsenPolicy decides the color of the leaves resulting of the collapse of
+// a subtree by Coarsen.
+type CoarsenPolicy int
+
+// Possible values for the CoarsenPolicy type.
+const (
+	// AnyBlack makes a leaf Black if any of its pixels is Black. It is the
+	// conservative policy for obstacle maps, and the one used when a quadtree
+	// is created.
+	AnyBlack CoarsenPolicy = iota
+
+	// Majority makes a leaf Black if at least half of its pixels are Black.
+	Majority
+
+	// AnyWhite makes a leaf White if any of its pixels is White, that is a
+	// leaf stays Black only if all its pixels are Black.
+	AnyWhite
+)
+
+// Coarsen returns a new quadtree, copy of q with a coarser resolution: the
+// subtrees of q lying at nodes that can't be subdivided at the new resolution
+// are collapsed into single leaves, the color of which is decided by policy.
+//
+// The returned quadtree has the same implementation and creation options as
+// q, except for the resolution. Coarsen returns the same errors as New if
+// the new resolution is invalid for the bounds of q.
+func Coarsen(q Quadtree, newResolution int, policy CoarsenPolicy) (Quadtree, error) {
+	o := optionsOf(q)
+	WithResolution(newResolution)(&o)
+	if err := o.validate(q.Root().Bounds()); err != nil {
+		return nil, err
+	}
+	root := coarsenNode(q.Root(), nil, 0, o, policy)
+	return newTree(implementationOf(q), root, o), nil
+}
+
+// coarsenNode returns a copy of the subtree rooted at n, lying at the given
+// depth, in which nodes that can't be subdivided are turned into leaves.
+func coarsenNode(n Node, parent *BasicNode, depth int, o options, policy CoarsenPolicy) *BasicNode {
+	cn := &BasicNode{
+		bounds:   n.Bounds(),
+		location: n.Location(),
+		color:    n.Color(),
+	}
+	if parent != nil {
+		cn.parent = parent
+	}
+	if n.Color() != Gray {
+		return cn
+	}
+	if !o.canSubdivide(n.Bounds(), depth) {
+		cn.color = policyColor(n, policy)
+		return cn
+	}
+	for q := Northwest; q <= Southeast; q++ {
+		cn.c[q] = coarsenNode(n.Child(q), cn, depth+1, o, policy)
+	}
+	compactNode(cn)
+	return cn
+}
+
+// policyColor returns the color of a leaf replacing the subtree rooted at n,
+// according to policy.
+func policyColor(n Node, policy CoarsenPolicy) Color {
+	black := blackArea(n)
+	b := n.Bounds()
+	area := b.Dx() * b.Dy()
+	switch {
+	case policy == AnyBlack && black > 0,
+		policy == Majority && 2*black >= area,
+		policy == AnyWhite && black == area:
+		return Black
+	}
+	return White
+}
+
+// blackArea returns the area of the Black leaves of the subtree rooted at n.
+func blackArea(n Node) int {
+	switch n.Color() {
+	case Black:
+		b := n.Bounds()
+		return b.Dx() * b.Dy()
+	case White:
+		return 0
+	}
+	area := 0
+	for q := Northwest; q <= Southeast; q++ {
+		area += blackArea(n.Child(q))
+	}
+	return area
+}
+
+// Refine returns a new quadtree, copy of q in which the nodes overlapping
+// region are subdivided again, from the content of scanner, down to a finer
+// resolution. Outside of region, the decomposition of q is kept as is.
+//
+// Nodes lying entirely inside region are created from scanner, as with New,
+// while nodes partially overlapping region are subdivided as long as the new
+// resolution allows it, and are then also created from scanner. The returned
+// quadtree has the same implementation and creation options as q, except for
+// the resolution. Refine returns ErrBounds if scanner doesn't cover the part
+// of region lying inside q, and the same errors as New if the new resolution
+// is invalid for the bounds of q.
+func Refine(q Quadtree, scanner imgscan.Scanner, region image.Rectangle, newResolution int) (Quadtree, error) {
+	bounds := q.Root().Bounds()
+	region = region.Intersect(bounds)
+	if !region.In(scanner.Bounds()) {
+		return nil, ErrBounds
+	}
+	o := optionsOf(q)
+	WithResolution(newResolution)(&o)
+	if err := o.validate(bounds); err != nil {
+		return nil, err
+	}
+	r := refinement{scanner: scanner, region: region, opts: o}
+	root := r.node(q.Root(), bounds, nil, Northwest, 0)
+	return newTree(implementationOf(q), root, o), nil
+}
+
+// refinement holds the parameters of a call to Refine.
+type refinement struct {
+	scanner imgscan.Scanner // source data
+	region  image.Rectangle // region to refine
+	opts    options         // options of the created quadtree
+}
+
+// node returns the node of given bounds of the refined quadtree. n is the
+// node of q of the same bounds or, if there's none, the leaf containing it.
+func (r *refinement) node(n Node, bounds image.Rectangle, parent *BasicNode, location Quadrant, depth int) *BasicNode {
+	rn := &BasicNode{
+		bounds:   bounds,
+		location: location,
+		color:    Gray,
+	}
+	if parent != nil {
+		rn.parent = parent
+	}
+
+	switch {
+	case !bounds.Overlaps(r.region):
+		// keep the decomposition of q
+		if n.Color() != Gray {
+			rn.color = n.Color()
+			return rn
+		}
+	case bounds.In(r.region):
+		if uniform, col := r.scanner.IsUniform(bounds); uniform {
+			rn.color = bitColor(col)
+			return rn
+		}
+		if !r.opts.canSubdivide(bounds, depth) {
+			rn.color = Black
+			return rn
+		}
+	default:
+		if !r.opts.canSubdivide(bounds, depth) {
+			if uniform, col := r.scanner.IsUniform(bounds.Intersect(r.scanner.Bounds())); uniform {
+				rn.color = bitColor(col)
+			} else {
+				rn.color = Black
+			}
+			return rn
+		}
+	}
+
+	for q, qb := range quadrants(bounds) {
+		rn.c[q] = r.node(subNode(n, Quadrant(q)), qb, rn, Quadrant(q), depth+1)
+	}
+	compactNode(rn)
+	return rn
+}
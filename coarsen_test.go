@@ -0,0 +1,132 @@
+package rquad
+
+import (
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/arl/go-rquad/internal"
+	"github.com/arl/imgtools/imgscan"
+)
+
+// blackPixels returns the number of black pixels of q in r.
+func blackPixels(q Quadtree, r image.Rectangle) int {
+	count := 0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if pixelColor(q, image.Pt(x, y)) == Black {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestCoarsen(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		for _, file := range []string{
+			"./testdata/labyrinth1.32x32.png",
+			"./testdata/labyrinth3.32x32.png",
+		} {
+			q := loadTree(t, file, WithImplementation(impl))
+			for _, res := range []int{2, 4, 8} {
+				for _, policy := range []CoarsenPolicy{AnyBlack, Majority, AnyWhite} {
+					t.Run(fmt.Sprintf("%v/%s/res=%d/policy=%d", impl, file, res, policy), func(t *testing.T) {
+						coarse, err := Coarsen(q, res, policy)
+						check(t, err)
+						if implementationOf(coarse) != impl {
+							t.Fatalf("got implementation %v, want %v", implementationOf(coarse), impl)
+						}
+						coarse.ForEachLeaf(Gray, func(n Node) {
+							b := n.Bounds()
+							black, area := blackPixels(q, b), b.Dx()*b.Dy()
+							want := White
+							switch {
+							case black == area:
+								want = Black
+							case black == 0:
+							case b.Dx()/2 >= res:
+								t.Fatalf("leaf %v is not uniform in the original quadtree", b)
+							case policy == AnyBlack, policy == Majority && 2*black >= area:
+								want = Black
+							}
+							if n.Color() != want {
+								t.Fatalf("leaf %v has %d black pixels out of %d, got %v, want %v", b, black, area, n.Color(), want)
+							}
+						})
+						checkCompact(t, coarse.Root())
+						checkNeighbours(t, coarse)
+
+						if policy == AnyBlack {
+							if want := loadTree(t, file, WithImplementation(impl), WithResolution(res)); !Equal(coarse, want) {
+								t.Errorf("coarsened quadtree differs from the one created at resolution %d", res)
+							}
+						}
+					})
+				}
+			}
+		}
+	}
+
+	q := loadTree(t, "./testdata/labyrinth1.32x32.png")
+	if _, err := Coarsen(q, 0, AnyBlack); err != ErrResolution {
+		t.Errorf("want ErrResolution, got %v", err)
+	}
+	if _, err := Coarsen(q, 32, AnyBlack); err != ErrTooSmall {
+		t.Errorf("want ErrTooSmall, got %v", err)
+	}
+}
+
+func TestRefine(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		for _, file := range []string{
+			"./testdata/labyrinth1.32x32.png",
+			"./testdata/labyrinth2.32x32.png",
+			"./testdata/labyrinth3.32x32.png",
+		} {
+			img, err := internal.LoadPNG(file)
+			check(t, err)
+			scanner, err := imgscan.NewScanner(img)
+			check(t, err)
+			fine := loadTree(t, file, WithImplementation(impl))
+			coarse := loadTree(t, file, WithImplementation(impl), WithResolution(8))
+
+			// refining the whole area
+			refined, err := Refine(coarse, scanner, image.Rect(-10, -10, 50, 50), 1)
+			check(t, err)
+			if !Equal(refined, fine) {
+				t.Errorf("%s: refining the whole quadtree should give the fine quadtree", file)
+			}
+			if got, want := refined.Root().Location(), fine.Root().Location(); got != want {
+				t.Errorf("%s: root location is %v, want %v", file, got, want)
+			}
+
+			// refining a region
+			region := image.Rect(5, 3, 21, 17)
+			refined, err = Refine(coarse, scanner, region, 1)
+			check(t, err)
+			if implementationOf(refined) != impl {
+				t.Fatalf("got implementation %v, want %v", implementationOf(refined), impl)
+			}
+			for y := 0; y < 32; y++ {
+				for x := 0; x < 32; x++ {
+					pt := image.Pt(x, y)
+					want := pixelColor(coarse, pt)
+					if pt.In(region) {
+						want = pixelColor(fine, pt)
+					}
+					if got := pixelColor(refined, pt); got != want {
+						t.Fatalf("%s: pixel %v is %v, want %v", file, pt, got, want)
+					}
+				}
+			}
+			checkCompact(t, refined.Root())
+			checkNeighbours(t, refined)
+		}
+	}
+
+	q := loadTree(t, "./testdata/labyrinth1.32x32.png")
+	if _, err := Refine(q, NewShapeScanner(image.Rect(0, 0, 8, 8)), image.Rect(0, 0, 16, 16), 1); err != ErrBounds {
+		t.Errorf("want ErrBounds, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package rquad
+
+import (
+	"testing"
+)
+
+func testCompact(t *testing.T, impl Implementation) {
+	fn := "./testdata/random-1024x1024.png"
+	for _, res := range []int{4, 16, 64} {
+		q := loadTree(t, fn, WithImplementation(impl), WithResolution(res))
+		orig := loadTree(t, fn, WithImplementation(impl), WithResolution(res))
+
+		var before, after int
+		q.ForEachLeaf(Gray, func(Node) { before++ })
+		q.(interface{ Compact() }).Compact()
+		q.ForEachLeaf(Gray, func(Node) { after++ })
+
+		if after >= before {
+			t.Errorf("res %d: got %d leaves after compaction, %d before", res, after, before)
+		}
+		if got := len(collectLeaves(q.Root(), nil)); got != after {
+			t.Errorf("res %d: got %d leaves in the tree, %d in the leaves slice", res, got, after)
+		}
+		checkCompact(t, q.Root())
+		checkNeighbours(t, q)
+		if !RegionEqual(q, orig) {
+			t.Errorf("res %d: compacted tree should be region-equal to the original", res)
+		}
+	}
+}
+
+func TestBasicTreeCompact(t *testing.T) {
+	testCompact(t, Basic)
+}
+
+func TestCNTreeCompact(t *testing.T) {
+	testCompact(t, CardinalNeighbour)
+}
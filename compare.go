@@ -0,0 +1,66 @@
+package rquad
+
+// Equal reports whether a and b have the same decomposition, that is their
+// nodes have the same bounds and the same colors. a and b can have different
+// implementations.
+func Equal(a, b Quadtree) bool {
+	_, differ := FirstDifference(a, b)
+	return !differ
+}
+
+// RegionEqual reports whether a and b represent the same regions, whatever
+// their decompositions. For example a Gray node having 4 Black leaf children
+// represents the same region as a Black leaf.
+func RegionEqual(a, b Quadtree) bool {
+	bounds := a.Root().Bounds()
+	if bounds != b.Root().Bounds() {
+		return false
+	}
+	return regionEqual(a.Root(), b.Root())
+}
+
+// FirstDifference returns the path to the first node that differs between a
+// and b, in terms of bounds, color, or decomposition. The path is the list of
+// quadrants to follow from the root node, the root node itself being
+// represented by an empty path. The nodes are compared in depth-first order.
+//
+// differ is false if a and b are Equal.
+func FirstDifference(a, b Quadtree) (path []Quadrant, differ bool) {
+	return firstDifference(a.Root(), b.Root(), nil)
+}
+
+// firstDifference returns the path of the first node that differs in the
+// subtrees rooted at a and b, which path is given.
+func firstDifference(a, b Node, path []Quadrant) ([]Quadrant, bool) {
+	if a.Bounds() != b.Bounds() || a.Color() != b.Color() {
+		return path, true
+	}
+	if a.Color() != Gray {
+		return nil, false
+	}
+	for q := Northwest; q <= Southeast; q++ {
+		qpath := append(path[:len(path):len(path)], q)
+		if diffPath, differ := firstDifference(a.Child(q), b.Child(q), qpath); differ {
+			return diffPath, true
+		}
+	}
+	return nil, false
+}
+
+// regionEqual reports whether a and b, which are either leaves covering the
+// same region, or gray nodes of the same bounds, represent the same region.
+func regionEqual(a, b Node) bool {
+	if a == b {
+		return true
+	}
+	ca, cb := a.Color(), b.Color()
+	if ca != Gray && cb != Gray {
+		return ca == cb
+	}
+	for q := Northwest; q <= Southeast; q++ {
+		if !regionEqual(subNode(a, q), subNode(b, q)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	files := []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+	}
+	for _, fn := range files {
+		basic := loadTree(t, fn, WithImplementation(Basic))
+		cn := loadTree(t, fn, WithImplementation(CardinalNeighbour))
+		if !Equal(basic, cn) {
+			path, _ := FirstDifference(basic, cn)
+			t.Errorf("%s: BasicTree and CNTree should be equal, first difference at %v", fn, path)
+		}
+		if !RegionEqual(basic, cn) {
+			t.Errorf("%s: BasicTree and CNTree should be region-equal", fn)
+		}
+		if !Equal(basic, Complement(Complement(cn))) {
+			t.Errorf("%s: double complement should be equal to the original", fn)
+		}
+		if Equal(basic, Complement(basic)) || RegionEqual(basic, Complement(basic)) {
+			t.Errorf("%s: complement shouldn't be equal to the original", fn)
+		}
+	}
+
+	// labyrinth2 has no white leaves of size 4, so the decomposition differs
+	// as soon as the resolution reaches 5.
+	a := loadTree(t, files[1], WithResolution(1))
+	b := loadTree(t, files[1], WithResolution(5))
+	if Equal(a, b) || RegionEqual(a, b) {
+		t.Errorf("trees with different resolutions shouldn't be equal")
+	}
+}
+
+func TestRegionEqual(t *testing.T) {
+	bounds := image.Rect(0, 0, 16, 16)
+	a := newUniformTree(bounds, Black)
+	b, err := Union(a, a)
+	check(t, err)
+
+	// b is compacted, it's made of a single black leaf
+	if !RegionEqual(a, b) {
+		t.Errorf("trees should be region-equal")
+	}
+	if Equal(a, b) {
+		t.Errorf("trees shouldn't be equal")
+	}
+	path, differ := FirstDifference(a, b)
+	if !differ || len(path) != 0 {
+		t.Errorf("first difference should be the root node, got %v, %t", path, differ)
+	}
+	if RegionEqual(a, newUniformTree(image.Rect(0, 0, 8, 8), Black)) {
+		t.Errorf("trees with different bounds shouldn't be region-equal")
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	fn := "./testdata/labyrinth2.32x32.png"
+	a := loadTree(t, fn)
+	b := loadTree(t, fn)
+
+	// change the color of a deep leaf
+	n := Locate(b, image.Pt(20, 9)).(*BasicNode)
+	n.color = White + Black - n.color
+
+	var want []Quadrant
+	for m := Node(n); m.Parent() != nil; m = m.Parent() {
+		want = append([]Quadrant{m.Location()}, want...)
+	}
+	path, differ := FirstDifference(a, b)
+	if !differ || !equalPaths(path, want) {
+		t.Errorf("got first difference at %v (%t), want %v", path, differ, want)
+	}
+}
+
+func equalPaths(a, b []Quadrant) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package rquad
+
+import "image"
+
+// Contour is a polygonal region, possibly with holes.
+//
+// Rings are not explicitly closed: the last point connects back to the first
+// one. Considering the y axis pointing downwards, as it does in images, the
+// outer ring is oriented clockwise and the holes counterclockwise, that is
+// the region always lies on the right of the ring edges. With the shoelace
+// formula, the outer ring area is positive while the holes areas are negative.
+type Contour struct {
+	Outer []image.Point   // outer ring
+	Holes [][]image.Point // hole rings
+}
+
+// Contours returns the polygons formed by the region of color c, which must be
+// Black or White.
+//
+// Adjacent leaves of color c are merged into polygons. Regions that only touch
+// each other by a corner are considered as distinct polygons. Consecutive
+// collinear edges are merged, so that the rings only contain the polygon
+// corners.
+func Contours(q Quadtree, c Color) []Contour {
+	var outers, holes [][]image.Point
+	for _, ring := range traceRings(Boundary(q, c)) {
+		if ringArea2(ring) > 0 {
+			outers = append(outers, ring)
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	contours := make([]Contour, len(outers))
+	polys := make([]*Polygon, len(outers))
+	for i, outer := range outers {
+		contours[i].Outer = outer
+		polys[i] = NewPolygon(toFPoints(outer)...)
+	}
+
+	// assign each hole to the smallest outer ring containing it
+	for _, hole := range holes {
+		pt := insidePoint(hole[0], hole[1])
+		best := -1
+		for i := range outers {
+			if !polys[i].contains(pt) {
+				continue
+			}
+			if best == -1 || ringArea2(outers[i]) < ringArea2(outers[best]) {
+				best = i
+			}
+		}
+		if best != -1 {
+			contours[best].Holes = append(contours[best].Holes, hole)
+		}
+	}
+	return contours
+}
+
+// traceRings links oriented boundary segments into rings.
+func traceRings(segs []Segment) [][]image.Point {
+	// index the segments by their starting point
+	starts := make(map[image.Point][]int, len(segs))
+	for i, s := range segs {
+		starts[s.A] = append(starts[s.A], i)
+	}
+
+	var rings [][]image.Point
+	used := make([]bool, len(segs))
+	for first := range segs {
+		if used[first] {
+			continue
+		}
+		var ring []image.Point
+		for cur := first; !used[cur]; {
+			used[cur] = true
+			ring = append(ring, segs[cur].A)
+
+			// follow the next segment, at pinch points (i.e where 2 regions
+			// touch by a corner), prefer turning right, so that the region,
+			// lying on the right, is kept separated from the other one.
+			next, bestTurn := -1, 0
+			dir := direction(segs[cur])
+			for _, i := range starts[segs[cur].B] {
+				if used[i] && i != first {
+					continue
+				}
+				if turn := turnRank(dir, direction(segs[i])); next == -1 || turn < bestTurn {
+					next, bestTurn = i, turn
+				}
+			}
+			if next == -1 {
+				break
+			}
+			cur = next
+		}
+		rings = append(rings, simplifyRing(ring))
+	}
+	return rings
+}
+
+// direction returns the unit vector of the horizontal or vertical segment s.
+func direction(s Segment) image.Point {
+	return s.B.Sub(s.A).Div(s.Len())
+}
+
+// turnRank ranks the turn from direction a to direction b, from 0 for a right
+// turn, the y axis pointing downwards, to 3 for a U-turn.
+func turnRank(a, b image.Point) int {
+	switch b {
+	case image.Pt(-a.Y, a.X):
+		// right turn
+		return 0
+	case a:
+		return 1
+	case image.Pt(a.Y, -a.X):
+		// left turn
+		return 2
+	}
+	return 3
+}
+
+// simplifyRing removes the points of ring lying between 2 collinear edges.
+func simplifyRing(ring []image.Point) []image.Point {
+	var res []image.Point
+	for i, pt := range ring {
+		prev := ring[(i+len(ring)-1)%len(ring)]
+		next := ring[(i+1)%len(ring)]
+		a, b := pt.Sub(prev), next.Sub(pt)
+		if a.X*b.Y-a.Y*b.X != 0 {
+			res = append(res, pt)
+		}
+	}
+	return res
+}
+
+// ringArea2 returns twice the signed area of ring, following the shoelace
+// formula.
+func ringArea2(ring []image.Point) int {
+	area := 0
+	for i, pt := range ring {
+		next := ring[(i+1)%len(ring)]
+		area += pt.X*next.Y - next.X*pt.Y
+	}
+	return area
+}
+
+// insidePoint returns a point lying slightly on the right of the middle of
+// the horizontal or vertical segment [a,b].
+func insidePoint(a, b image.Point) FPoint {
+	d := direction(Segment{a, b})
+	return FPoint{
+		X: float64(a.X+b.X)/2 - float64(d.Y)/4,
+		Y: float64(a.Y+b.Y)/2 + float64(d.X)/4,
+	}
+}
+
+func toFPoints(pts []image.Point) []FPoint {
+	fpts := make([]FPoint, len(pts))
+	for i, pt := range pts {
+		fpts[i] = fpoint(pt)
+	}
+	return fpts
+}
@@ -0,0 +1,107 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+)
+
+func testContours(t *testing.T, impl Implementation) {
+	var testTbl = []struct {
+		fn  string
+		res int
+	}{
+		{"./testdata/labyrinth1.32x32.png", 1},
+		{"./testdata/labyrinth2.32x32.png", 1},
+		{"./testdata/labyrinth3.32x32.png", 1},
+		{"./testdata/labyrinth4.8x8.png", 1},
+		{"./testdata/random-1024x1024.png", 32},
+	}
+
+	for _, tt := range testTbl {
+		q := loadTree(t, tt.fn, WithImplementation(impl), WithResolution(tt.res))
+		for _, c := range []Color{Black, White} {
+			contours := Contours(q, c)
+
+			area := 0
+			polys := make([]*Polygon, len(contours))
+			holes := make([][]*Polygon, len(contours))
+			for i, ctr := range contours {
+				if a := ringArea2(ctr.Outer); a <= 0 {
+					t.Fatalf("%s %v: outer ring has area %d", tt.fn, c, a)
+				}
+				area += ringArea2(ctr.Outer)
+				polys[i] = NewPolygon(toFPoints(ctr.Outer)...)
+				for _, hole := range ctr.Holes {
+					if a := ringArea2(hole); a >= 0 {
+						t.Fatalf("%s %v: hole ring has area %d", tt.fn, c, a)
+					}
+					area += ringArea2(hole)
+					holes[i] = append(holes[i], NewPolygon(toFPoints(hole)...))
+				}
+			}
+			if want := 2 * Area(q, c); area != want {
+				t.Errorf("%s %v: got contours area %d, want %d", tt.fn, c, area/2, want/2)
+			}
+
+			// the center of each pixel of color c must be in exactly one
+			// contour, outside of its holes.
+			b := q.Root().Bounds()
+			for y := b.Min.Y; y < b.Max.Y; y += tt.res {
+				for x := b.Min.X; x < b.Max.X; x += tt.res {
+					center := FPoint{float64(x) + 0.5, float64(y) + 0.5}
+					in := 0
+					for i := range polys {
+						if !polys[i].contains(center) {
+							continue
+						}
+						inHole := false
+						for _, h := range holes[i] {
+							inHole = inHole || h.contains(center)
+						}
+						if !inHole {
+							in++
+						}
+					}
+					want := 0
+					if Locate(q, image.Pt(x, y)).Color() == c {
+						want = 1
+					}
+					if in != want {
+						t.Fatalf("%s %v: pixel (%d,%d) is in %d contours, want %d", tt.fn, c, x, y, in, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBasicTreeContours(t *testing.T) {
+	testContours(t, Basic)
+}
+
+func TestCNTreeContours(t *testing.T) {
+	testContours(t, CardinalNeighbour)
+}
+
+func TestContoursHole(t *testing.T) {
+	// a 16x16 black square with a 4x4 white hole in its middle
+	shapes := []Shape{
+		NewPolygon(FPoint{0, 0}, FPoint{16, 0}, FPoint{16, 6}, FPoint{0, 6}),
+		NewPolygon(FPoint{0, 10}, FPoint{16, 10}, FPoint{16, 16}, FPoint{0, 16}),
+		NewPolygon(FPoint{0, 6}, FPoint{6, 6}, FPoint{6, 10}, FPoint{0, 10}),
+		NewPolygon(FPoint{10, 6}, FPoint{16, 6}, FPoint{16, 10}, FPoint{10, 10}),
+	}
+	q, err := New(NewShapeScanner(image.Rect(0, 0, 16, 16), shapes...))
+	check(t, err)
+
+	contours := Contours(q, Black)
+	if len(contours) != 1 || len(contours[0].Outer) != 4 || len(contours[0].Holes) != 1 {
+		t.Fatalf("want 1 square contour with 1 hole, got %v", contours)
+	}
+	if a := ringArea2(contours[0].Holes[0]); a != -2*16 {
+		t.Errorf("got hole area %d, want -16", a/2)
+	}
+	if white := Contours(q, White); len(white) != 1 || len(white[0].Holes) != 0 {
+		t.Errorf("want 1 white contour without holes, got %v", white)
+	}
+}
@@ -0,0 +1,47 @@
+package rquad
+
+// DataNode is the interface implemented by nodes to which user data can be
+// attached. All the nodes created by this package implement it.
+//
+// As nodes don't have an identity other than their pointer, the data of a
+// quadtree can be serialized along with the locational codes of the nodes
+// (see Code), and attached again to the nodes returned by NodeByCode.
+type DataNode interface {
+	Node
+
+	// Data returns the user data attached to the node, or nil.
+	Data() interface{}
+
+	// SetData attaches arbitrary user data to the node.
+	SetData(data interface{})
+}
+
+// Clone returns a deep copy of q, of the same implementation and with the
+// same creation options, in which every node holds the same user data as the
+// node of q it's a copy of. Quadtrees not created by New, NewBasicTree or
+// NewCNTree are cloned as BasicTree.
+func Clone(q Quadtree) Quadtree {
+	root := cloneNode(q.Root(), nil)
+	return newTree(implementationOf(q), root, optionsOf(q))
+}
+
+// cloneNode returns a copy of the subtree rooted at n.
+func cloneNode(n Node, parent *BasicNode) *BasicNode {
+	cn := &BasicNode{
+		bounds:   n.Bounds(),
+		location: n.Location(),
+		color:    n.Color(),
+	}
+	if parent != nil {
+		cn.parent = parent
+	}
+	if dn, ok := n.(DataNode); ok {
+		cn.data = dn.Data()
+	}
+	if n.Color() == Gray {
+		for q := Northwest; q <= Southeast; q++ {
+			cn.c[q] = cloneNode(n.Child(q), cn)
+		}
+	}
+	return cn
+}
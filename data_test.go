@@ -0,0 +1,53 @@
+package rquad
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+func TestNodeData(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth3.32x32.png", WithImplementation(impl))
+		for _, n := range allNodes(q.Root()) {
+			n.(DataNode).SetData(Code(n).String())
+		}
+
+		clone := Clone(q)
+		if implementationOf(clone) != impl || !Equal(q, clone) {
+			t.Fatalf("clone differs from the original quadtree")
+		}
+		checkNeighbours(t, clone)
+		for _, n := range allNodes(clone.Root()) {
+			if n == NodeByCode(q, Code(n)) {
+				t.Fatalf("clone shares node %v with the original quadtree", n.Bounds())
+			}
+			if got, want := n.(DataNode).Data(), Code(n).String(); got != want {
+				t.Fatalf("node %v holds data %v, want %v", n.Bounds(), got, want)
+			}
+			n.(DataNode).SetData(nil)
+		}
+		for _, n := range allNodes(q.Root()) {
+			if got, want := n.(DataNode).Data(), Code(n).String(); got != want {
+				t.Fatalf("modifying the clone modified the data of node %v", n.Bounds())
+			}
+		}
+
+		// data is kept by in-place modifications, for the nodes that remain
+		far := Locate(q, image.Pt(31, 31))
+		q.(draw.Image).Set(0, 0, binimg.White)
+		q.(draw.Image).Set(1, 0, binimg.Black)
+		if Locate(q, image.Pt(31, 31)) != far || far.(DataNode).Data() != Code(far).String() {
+			t.Errorf("data of an untouched leaf has been modified")
+		}
+	}
+
+	pt, err := NewPointTree(image.Rect(0, 0, 8, 8), 1)
+	check(t, err)
+	pt.Root().(DataNode).SetData(42)
+	if pt.Root().(DataNode).Data() != 42 {
+		t.Errorf("PointTree nodes should hold data")
+	}
+}
@@ -0,0 +1,63 @@
+package rquad
+
+import "image"
+
+// Change describes a rectangular region which color differs between 2
+// quadtrees.
+type Change struct {
+	Bounds image.Rectangle // changed region
+	Old    Color           // color of the region in the first quadtree
+	New    Color           // color of the region in the second quadtree
+}
+
+// Diff returns the regions which color differs between a and b.
+//
+// Diff walks both quadtrees simultaneously, a and b can have different
+// implementations but must have the same bounds, or ErrBounds is returned.
+// Identical subtrees are skipped and the returned changes are merged
+// whenever possible: 4 sibling regions showing the same color change are
+// reported as a single change of their parent region.
+func Diff(a, b Quadtree) ([]Change, error) {
+	bounds := a.Root().Bounds()
+	if bounds != b.Root().Bounds() {
+		return nil, ErrBounds
+	}
+	return diffNodes(a.Root(), b.Root(), bounds), nil
+}
+
+// diffNodes returns the changes between a and b, which are either leaves
+// covering bounds, or gray nodes of the same bounds.
+func diffNodes(a, b Node, bounds image.Rectangle) []Change {
+	if a == b {
+		return nil
+	}
+	ca, cb := a.Color(), b.Color()
+	if ca != Gray && cb != Gray {
+		if ca == cb {
+			return nil
+		}
+		return []Change{{Bounds: bounds, Old: ca, New: cb}}
+	}
+
+	var (
+		changes []Change
+		whole   [4]bool // the whole quadrant has changed
+	)
+	for q, qb := range quadrants(bounds) {
+		qchanges := diffNodes(subNode(a, Quadrant(q)), subNode(b, Quadrant(q)), qb)
+		whole[q] = len(qchanges) == 1 && qchanges[0].Bounds == qb
+		changes = append(changes, qchanges...)
+	}
+
+	// merge the changes if the 4 quadrants have changed the same way
+	if len(changes) == 4 && whole[0] && whole[1] && whole[2] && whole[3] {
+		first := changes[0]
+		for _, c := range changes[1:] {
+			if c.Old != first.Old || c.New != first.New {
+				return changes
+			}
+		}
+		return []Change{{Bounds: bounds, Old: first.Old, New: first.New}}
+	}
+	return changes
+}
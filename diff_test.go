@@ -0,0 +1,82 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	files := []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+	}
+
+	for _, fa := range files {
+		for _, fb := range files {
+			a := loadTree(t, fa, WithImplementation(Basic))
+			b := loadTree(t, fb, WithImplementation(CardinalNeighbour), WithResolution(2))
+			changes, err := Diff(a, b)
+			check(t, err)
+
+			// each pixel must be covered by, at most, one change
+			bounds := a.Root().Bounds()
+			changed := make(map[image.Point]Change)
+			for _, c := range changes {
+				if c.Old == c.New {
+					t.Fatalf("Diff(%s, %s): change %v has the same colors", fa, fb, c)
+				}
+				for y := c.Bounds.Min.Y; y < c.Bounds.Max.Y; y++ {
+					for x := c.Bounds.Min.X; x < c.Bounds.Max.X; x++ {
+						pt := image.Pt(x, y)
+						if _, ok := changed[pt]; ok {
+							t.Fatalf("Diff(%s, %s): %v is covered by many changes", fa, fb, pt)
+						}
+						changed[pt] = c
+					}
+				}
+			}
+
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					pt := image.Pt(x, y)
+					ca, cb := Locate(a, pt).Color(), Locate(b, pt).Color()
+					c, ok := changed[pt]
+					if ok != (ca != cb) {
+						t.Fatalf("Diff(%s, %s): %v changed = %t, colors %v and %v", fa, fb, pt, ok, ca, cb)
+					}
+					if ok && (c.Old != ca || c.New != cb) {
+						t.Fatalf("Diff(%s, %s): %v got change %v -> %v, want %v -> %v", fa, fb, pt, c.Old, c.New, ca, cb)
+					}
+				}
+			}
+		}
+	}
+}
+
+// newUniformTree returns a non-compact BasicTree which root has 4 leaf
+// children of the color c.
+func newUniformTree(bounds image.Rectangle, c Color) Quadtree {
+	root := &BasicNode{bounds: bounds, color: Gray}
+	for q, qb := range quadrants(bounds) {
+		root.c[q] = &BasicNode{bounds: qb, color: c, parent: root, location: Quadrant(q)}
+	}
+	return newTree(Basic, root, newOptions())
+}
+
+func TestDiffMerge(t *testing.T) {
+	bounds := image.Rect(0, 0, 32, 32)
+	changes, err := Diff(newUniformTree(bounds, White), newUniformTree(bounds, Black))
+	check(t, err)
+	want := []Change{{Bounds: bounds, Old: White, New: Black}}
+	if len(changes) != 1 || changes[0] != want[0] {
+		t.Errorf("got changes %v, want %v", changes, want)
+	}
+
+	a := loadTree(t, "./testdata/labyrinth2.32x32.png")
+	changes, err = Diff(a, a)
+	check(t, err)
+	if len(changes) != 0 {
+		t.Errorf("want no changes, got %v", changes)
+	}
+}
@@ -0,0 +1,175 @@
+package rquad
+
+import (
+	"image"
+	"math"
+)
+
+// DistanceField is a distance transform of a quadtree, it holds for each leaf
+// the distance to the nearest Black leaf, i.e the clearance.
+type DistanceField struct {
+	q       Quadtree
+	nearest map[Node][]Node  // nearest black leaves (there may be ties)
+	dist    map[Node]float64 // distance to the nearest black leaf
+}
+
+// NewDistanceField computes the distance transform of q.
+//
+// The distance between 2 leaves is the Euclidean distance between their
+// rectangles, so it's 0 for adjacent leaves. Distances are propagated from the
+// Black leaves to the White ones, through ForEachNeighbour, in order of
+// increasing distance, each White leaf inheriting the nearest Black leaves of
+// its neighbours. As with all propagation-based distance transforms, this
+// may overestimate the distance of a few leaves, so the propagated distance
+// of each White leaf is then used to bound a best-first search of the
+// hierarchy, which gives the exact distance.
+func NewDistanceField(q Quadtree) *DistanceField {
+	f := &DistanceField{
+		q:       q,
+		nearest: make(map[Node][]Node),
+		dist:    make(map[Node]float64),
+	}
+
+	var pq priorityQueue
+	q.ForEachLeaf(Black, func(n Node) {
+		f.nearest[n] = []Node{n}
+		f.dist[n] = 0
+		pq.push(queueItem{node: n})
+	})
+
+	for len(pq) > 0 {
+		item := pq.pop()
+		if item.dist > f.dist[item.node] {
+			// stale item
+			continue
+		}
+		srcs := f.nearest[item.node]
+		ForEachNeighbour(item.node, func(nb Node) {
+			if nb.Color() == Black {
+				return
+			}
+			changed := false
+			for _, src := range srcs {
+				d := rectDist(nb.Bounds(), src.Bounds())
+				cur, ok := f.dist[nb]
+				switch {
+				case !ok || d < cur:
+					f.dist[nb] = d
+					f.nearest[nb] = []Node{src}
+					changed = true
+				case d == cur && !containsNode(f.nearest[nb], src):
+					// keep track of ties, as equally near black leaves
+					// may not be so for the neighbours of nb.
+					f.nearest[nb] = append(f.nearest[nb], src)
+					changed = true
+				}
+			}
+			if changed {
+				pq.push(queueItem{node: nb, dist: f.dist[nb]})
+			}
+		})
+	}
+
+	q.ForEachLeaf(White, func(n Node) {
+		bound, ok := f.dist[n]
+		if !ok {
+			// no black leaves
+			return
+		}
+		b := n.Bounds()
+		dist := func(r image.Rectangle) float64 { return rectDist(b, r) }
+		if black, d := nearestBlack(q.Root(), dist, bound); black != nil {
+			f.dist[n] = d
+			f.nearest[n] = []Node{black}
+		}
+	})
+	return f
+}
+
+// Distance returns the distance between the leaf n and the nearest Black
+// leaf, 0 if n is Black, or +Inf if the quadtree has no Black leaves.
+func (f *DistanceField) Distance(n Node) float64 {
+	if d, ok := f.dist[n]; ok {
+		return d
+	}
+	return math.Inf(1)
+}
+
+// Nearest returns the nearest Black leaf of the leaf n, n itself if it's
+// Black, or nil if the quadtree has no Black leaves.
+func (f *DistanceField) Nearest(n Node) Node {
+	if nearest := f.nearest[n]; len(nearest) > 0 {
+		return nearest[0]
+	}
+	return nil
+}
+
+// Clearance returns the distance between the center of the pixel at pt and
+// the nearest Black leaf.
+//
+// Clearance returns 0 if pt lies in a Black leaf or outside of the quadtree,
+// and +Inf if the quadtree has no Black leaves.
+func (f *DistanceField) Clearance(pt image.Point) float64 {
+	n := Locate(f.q, pt)
+	switch {
+	case n == nil:
+		return 0
+	case f.Nearest(n) == nil:
+		return math.Inf(1)
+	}
+
+	// The nearest Black leaf of the leaf containing pt is not necessarily
+	// the nearest one of pt, but it bounds the search of the hierarchy.
+	center := pixelCenter(pt)
+	dist := func(r image.Rectangle) float64 { return toFRect(r).pointDist(center) }
+	bound := dist(f.Nearest(n).Bounds())
+	if black, d := nearestBlack(f.q.Root(), dist, bound); black != nil {
+		return d
+	}
+	return bound
+}
+
+// nearestBlack performs a best-first search of the subtree rooted at n, and
+// returns the Black leaf minimizing dist, along with its distance, if that
+// distance is lower than bound. Otherwise, nearestBlack returns nil and
+// bound.
+//
+// dist must not decrease from a node to its descendants.
+func nearestBlack(n Node, dist func(image.Rectangle) float64, bound float64) (Node, float64) {
+	var pq priorityQueue
+	if d := dist(n.Bounds()); d < bound {
+		pq.push(queueItem{node: n, dist: d})
+	}
+	for len(pq) > 0 {
+		item := pq.pop()
+		switch item.node.Color() {
+		case Black:
+			return item.node, item.dist
+		case Gray:
+			for q := Northwest; q <= Southeast; q++ {
+				child := item.node.Child(q)
+				if d := dist(child.Bounds()); d < bound {
+					pq.push(queueItem{node: child, dist: d})
+				}
+			}
+		}
+	}
+	return nil, bound
+}
+
+// rectDist returns the Euclidean distance between the rectangles a and b.
+func rectDist(a, b image.Rectangle) float64 {
+	dx := max(0, max(b.Min.X-a.Max.X, a.Min.X-b.Max.X))
+	dy := max(0, max(b.Min.Y-a.Max.Y, a.Min.Y-b.Max.Y))
+	return math.Hypot(float64(dx), float64(dy))
+}
+
+// containsNode reports whether nodes contains n.
+func containsNode(nodes []Node, n Node) bool {
+	for _, m := range nodes {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
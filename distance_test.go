@@ -0,0 +1,162 @@
+package rquad
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkDistanceField checks the distance field of q against brute force.
+func checkDistanceField(t *testing.T, q Quadtree) {
+	t.Helper()
+	f := NewDistanceField(q)
+
+	var blacks []Node
+	q.ForEachLeaf(Black, func(n Node) { blacks = append(blacks, n) })
+
+	q.ForEachLeaf(Gray, func(n Node) {
+		want := math.Inf(1)
+		for _, b := range blacks {
+			want = math.Min(want, rectDist(n.Bounds(), b.Bounds()))
+		}
+		got := f.Distance(n)
+		if got != want {
+			t.Fatalf("leaf %v has distance %v, want %v", n.Bounds(), got, want)
+		}
+		if len(blacks) == 0 {
+			return
+		}
+		if d := rectDist(n.Bounds(), f.Nearest(n).Bounds()); d != got {
+			t.Fatalf("leaf %v has distance %v, but is at %v from its nearest black leaf", n.Bounds(), got, d)
+		}
+	})
+
+	b := q.Root().Bounds()
+	step := max(3, b.Dx()/16)
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			pt := image.Pt(x, y)
+			want := math.Inf(1)
+			for _, b := range blacks {
+				want = math.Min(want, toFRect(b.Bounds()).pointDist(pixelCenter(pt)))
+			}
+			if got := f.Clearance(pt); got != want {
+				t.Fatalf("Clearance(%v) = %v, want %v", pt, got, want)
+			}
+		}
+	}
+}
+
+// randomRectsTree returns a quadtree which Black region is made of n random
+// rectangles.
+func randomRectsTree(t *testing.T, r *rand.Rand, size, n int, impl Implementation) Quadtree {
+	t.Helper()
+	var shapes []Shape
+	for i := 0; i < n; i++ {
+		x, y := float64(r.Intn(size)), float64(r.Intn(size))
+		w, h := float64(r.Intn(size/4)+1), float64(r.Intn(size/4)+1)
+		shapes = append(shapes, NewPolygon(FPoint{x, y}, FPoint{x + w, y}, FPoint{x + w, y + h}, FPoint{x, y + h}))
+	}
+	q, err := New(NewShapeScanner(image.Rect(0, 0, size, size), shapes...), WithImplementation(impl))
+	check(t, err)
+	return q
+}
+
+func testDistanceField(t *testing.T, impl Implementation) {
+	var testTbl = []struct {
+		fn  string
+		res int
+	}{
+		{"./testdata/labyrinth1.32x32.png", 1},
+		{"./testdata/labyrinth2.32x32.png", 1},
+		{"./testdata/labyrinth3.32x32.png", 1},
+		{"./testdata/random-1024x1024.png", 16},
+	}
+
+	for _, tt := range testTbl {
+		t.Run(tt.fn, func(t *testing.T) {
+			checkDistanceField(t, loadTree(t, tt.fn, WithImplementation(impl), WithResolution(tt.res)))
+		})
+	}
+
+	r := rand.New(rand.NewSource(99))
+	for i := 0; i < 200; i++ {
+		checkDistanceField(t, randomRectsTree(t, r, 64, 1+r.Intn(6), impl))
+	}
+}
+
+func TestBasicTreeDistanceField(t *testing.T) {
+	testDistanceField(t, Basic)
+}
+
+func TestCNTreeDistanceField(t *testing.T) {
+	testDistanceField(t, CardinalNeighbour)
+}
+
+func TestDistanceFieldClearance(t *testing.T) {
+	// black square at (8,8)-(16,16)
+	shapes := []Shape{NewPolygon(FPoint{8, 8}, FPoint{16, 8}, FPoint{16, 16}, FPoint{8, 16})}
+	q, err := New(NewShapeScanner(image.Rect(0, 0, 32, 32), shapes...))
+	check(t, err)
+	f := NewDistanceField(q)
+
+	var testTbl = []struct {
+		pt   image.Point
+		want float64
+	}{
+		{image.Pt(10, 10), 0},
+		{image.Pt(7, 10), 0.5},
+		{image.Pt(20, 12), 4.5},
+		{image.Pt(-1, 0), 0},
+	}
+	for _, tt := range testTbl {
+		if got := f.Clearance(tt.pt); got != tt.want {
+			t.Errorf("Clearance(%v) = %v, want %v", tt.pt, got, tt.want)
+		}
+	}
+
+	// the nearest black leaf of a point lying in a large white leaf is not
+	// necessarily the nearest black leaf of that leaf.
+	q, err = New(NewShapeScanner(image.Rect(0, 0, 32, 32),
+		NewPolygon(FPoint{0, 8}, FPoint{8, 8}, FPoint{8, 16}, FPoint{0, 16}),
+		NewPolygon(FPoint{16, 8}, FPoint{24, 8}, FPoint{24, 16}, FPoint{16, 16}),
+	))
+	check(t, err)
+	f = NewDistanceField(q)
+	for _, tt := range []struct {
+		pt   image.Point
+		want float64
+	}{
+		{image.Pt(15, 12), 0.5},
+		{image.Pt(8, 12), 0.5},
+		{image.Pt(12, 4), math.Hypot(3.5, 3.5)},
+		{image.Pt(30, 30), math.Hypot(6.5, 14.5)},
+	} {
+		if got := f.Clearance(tt.pt); got != tt.want {
+			t.Errorf("Clearance(%v) = %v, want %v", tt.pt, got, tt.want)
+		}
+	}
+
+	// propagation through neighbours alone overestimates the distance of
+	// the leaf (0,12)-(4,16).
+	q, err = New(NewShapeScanner(image.Rect(0, 0, 16, 16),
+		NewPolygon(FPoint{9, 10}, FPoint{13, 10}, FPoint{13, 14}, FPoint{9, 14}),
+		NewPolygon(FPoint{0, 7}, FPoint{2, 7}, FPoint{2, 8}, FPoint{0, 8}),
+		NewPolygon(FPoint{7, 9}, FPoint{9, 9}, FPoint{9, 10}, FPoint{7, 10}),
+	))
+	check(t, err)
+	n := Locate(q, image.Pt(0, 12))
+	if n.Bounds() != image.Rect(0, 12, 4, 16) {
+		t.Fatalf("got leaf %v, want (0,12)-(4,16)", n.Bounds())
+	}
+	if got, want := NewDistanceField(q).Distance(n), math.Hypot(2, 3); got != want {
+		t.Errorf("leaf %v has distance %v, want %v", n.Bounds(), got, want)
+	}
+
+	empty, err := New(NewShapeScanner(image.Rect(0, 0, 32, 32)))
+	check(t, err)
+	if got := NewDistanceField(empty).Clearance(image.Pt(3, 3)); !math.IsInf(got, 1) {
+		t.Errorf("Clearance in a quadtree without black leaves = %v, want +Inf", got)
+	}
+}
@@ -0,0 +1,72 @@
+package rquad
+
+import (
+	"errors"
+	"image"
+)
+
+var (
+	// ErrColor is returned when a leaf color is expected to be Black or
+	// White, and is Gray.
+	ErrColor = errors.New("leaf color must be Black or White")
+
+	// ErrReadOnly is returned when trying to modify a quadtree that doesn't
+	// support it.
+	ErrReadOnly = errors.New("quadtree can't be modified")
+)
+
+// FloodFill calls fn for each leaf of the region containing the pixel seed,
+// that is the set of leaves of the same color as the seed leaf that can be
+// reached from it through neighbours of the same color. If seed lies outside
+// of q, fn is never called.
+//
+// The region is explored breadth-first, iteratively, so that large regions
+// don't grow the call stack. Neighbours are obtained through
+// ForEachNeighbour, in constant time on CNTree leaves.
+func FloodFill(q Quadtree, seed image.Point, fn func(Node)) {
+	start := Locate(q, seed)
+	if start == nil {
+		return
+	}
+	col := start.Color()
+	visited := map[Node]bool{start: true}
+	queue := []Node{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		fn(n)
+		ForEachNeighbour(n, func(nb Node) {
+			if nb.Color() == col && !visited[nb] {
+				visited[nb] = true
+				queue = append(queue, nb)
+			}
+		})
+	}
+}
+
+// FloodFillColor sets to c the color of the leaves forming the region
+// containing the pixel seed, as defined by FloodFill.
+//
+// Only quadtrees created by New, NewBasicTree or NewCNTree can be modified,
+// FloodFillColor returns ErrReadOnly for others, and ErrColor if c is Gray.
+// Recolored leaves are not merged with their siblings, Compact can be called
+// for that.
+func FloodFillColor(q Quadtree, seed image.Point, c Color) error {
+	switch q.(type) {
+	case *BasicTree, *CNTree:
+	default:
+		return ErrReadOnly
+	}
+	if c == Gray {
+		return ErrColor
+	}
+
+	var region []*BasicNode
+	FloodFill(q, seed, func(n Node) {
+		region = append(region, n.(basicNoder).basicNode())
+	})
+	for _, n := range region {
+		n.color = c
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+)
+
+// bruteFloodFill returns the set of pixels 4-connected to seed having the
+// same color.
+func bruteFloodFill(q Quadtree, seed image.Point) map[image.Point]bool {
+	col := pixelColor(q, seed)
+	b := q.Root().Bounds()
+	region := map[image.Point]bool{seed: true}
+	stack := []image.Point{seed}
+	for len(stack) > 0 {
+		pt := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, d := range []image.Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nb := pt.Add(d)
+			if nb.In(b) && !region[nb] && pixelColor(q, nb) == col {
+				region[nb] = true
+				stack = append(stack, nb)
+			}
+		}
+	}
+	return region
+}
+
+func testFloodFill(t *testing.T, impl Implementation) {
+	for _, file := range []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+	} {
+		q := loadTree(t, file, WithImplementation(impl))
+		for _, seed := range []image.Point{{0, 0}, {5, 9}, {16, 16}, {31, 31}} {
+			want := bruteFloodFill(q, seed)
+			col := pixelColor(q, seed)
+
+			area := 0
+			visited := make(map[Node]bool)
+			FloodFill(q, seed, func(n Node) {
+				if visited[n] {
+					t.Fatalf("%s: leaf %v visited twice", file, n.Bounds())
+				}
+				visited[n] = true
+				if n.Color() != col {
+					t.Fatalf("%s: leaf %v has color %v, want %v", file, n.Bounds(), n.Color(), col)
+				}
+				if !want[n.Bounds().Min] {
+					t.Fatalf("%s: leaf %v is not reachable from %v", file, n.Bounds(), seed)
+				}
+				area += n.Bounds().Dx() * n.Bounds().Dy()
+			})
+			if area != len(want) {
+				t.Fatalf("%s: flood fill from %v covers %d pixels, want %d", file, seed, area, len(want))
+			}
+		}
+	}
+}
+
+func TestBasicTreeFloodFill(t *testing.T) {
+	testFloodFill(t, Basic)
+}
+
+func TestCNTreeFloodFill(t *testing.T) {
+	testFloodFill(t, CardinalNeighbour)
+}
+
+func TestFloodFillColor(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth2.32x32.png", WithImplementation(impl))
+		seed := image.Pt(0, 0)
+		region := bruteFloodFill(q, seed)
+		col := pixelColor(q, seed)
+		newCol := Black
+		if col == Black {
+			newCol = White
+		}
+
+		before := make(map[image.Point]Color)
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				before[image.Pt(x, y)] = pixelColor(q, image.Pt(x, y))
+			}
+		}
+
+		check(t, FloodFillColor(q, seed, newCol))
+		for pt, c := range before {
+			want := c
+			if region[pt] {
+				want = newCol
+			}
+			if got := pixelColor(q, pt); got != want {
+				t.Fatalf("pixel %v has color %v after flood fill, want %v", pt, got, want)
+			}
+		}
+
+		count := 0
+		q.ForEachLeaf(newCol, func(Node) { count++ })
+		if count == 0 {
+			t.Errorf("ForEachLeaf doesn't see the recolored leaves")
+		}
+		if err := FloodFillColor(q, seed, Gray); err != ErrColor {
+			t.Errorf("want ErrColor, got %v", err)
+		}
+	}
+
+	pq, err := NewPointTree(image.Rect(0, 0, 8, 8), 1)
+	check(t, err)
+	if err := FloodFillColor(pq, image.Pt(0, 0), Black); err != ErrReadOnly {
+		t.Errorf("want ErrReadOnly, got %v", err)
+	}
+}
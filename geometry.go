@@ -0,0 +1,301 @@
+package rquad
+
+import (
+	"image"
+	"math"
+)
+
+// FPoint is a point with floating-point coordinates, expressed in the same
+// coordinate space as the quadtree nodes bounds.
+type FPoint struct {
+	X, Y float64
+}
+
+// fpoint returns the FPoint located at the top-left corner of the pixel p.
+func fpoint(p image.Point) FPoint {
+	return FPoint{float64(p.X), float64(p.Y)}
+}
+
+// A Shape is a 2D geometric primitive that can be tested for intersection
+// with rectangles.
+//
+// Rectangles are considered as continuous areas, so that a shape that only
+// touches the border of a rectangle does not overlap it.
+type Shape interface {
+	// Overlaps reports whether the shape intersects the interior of r.
+	Overlaps(r image.Rectangle) bool
+
+	// Covers reports whether r lies entirely inside the shape.
+	Covers(r image.Rectangle) bool
+}
+
+// Polygon is a simple (i.e non self-intersecting) polygon, convex or not.
+type Polygon struct {
+	pts []FPoint
+	box frect
+}
+
+// NewPolygon creates a polygon from its vertices, the polygon is implicitly
+// closed. NewPolygon panics if less than 3 vertices are provided.
+func NewPolygon(pts ...FPoint) *Polygon {
+	if len(pts) < 3 {
+		panic("a polygon must have at least 3 vertices")
+	}
+	return &Polygon{pts: pts, box: boundingBox(pts)}
+}
+
+// Overlaps reports whether the polygon intersects the interior of r.
+func (p *Polygon) Overlaps(r image.Rectangle) bool {
+	fr := toFRect(r)
+	if !p.box.overlaps(fr) {
+		return false
+	}
+	if p.crosses(fr) {
+		return true
+	}
+	// no edge enters r, so r is either inside or outside of the polygon.
+	return p.contains(fr.center())
+}
+
+// Covers reports whether r lies entirely inside the polygon.
+func (p *Polygon) Covers(r image.Rectangle) bool {
+	fr := toFRect(r)
+	if !p.box.covers(fr) {
+		return false
+	}
+	return !p.crosses(fr) && p.contains(fr.center())
+}
+
+// crosses reports whether any polygon edge intersects the interior of r.
+func (p *Polygon) crosses(r frect) bool {
+	prev := p.pts[len(p.pts)-1]
+	for _, cur := range p.pts {
+		if r.crossedBy(prev, cur) {
+			return true
+		}
+		prev = cur
+	}
+	return false
+}
+
+// contains reports whether pt lies inside the polygon, following the even-odd
+// rule.
+func (p *Polygon) contains(pt FPoint) bool {
+	in := false
+	prev := p.pts[len(p.pts)-1]
+	for _, cur := range p.pts {
+		if (cur.Y > pt.Y) != (prev.Y > pt.Y) &&
+			pt.X < (prev.X-cur.X)*(pt.Y-cur.Y)/(prev.Y-cur.Y)+cur.X {
+			in = !in
+		}
+		prev = cur
+	}
+	return in
+}
+
+// Polyline is a thick polyline, that is the set of points lying within half
+// its width of any of its segments. A polyline made of a single point
+// represents a disc.
+type Polyline struct {
+	pts    []FPoint
+	radius float64
+	box    frect
+}
+
+// NewPolyline creates a polyline of the given width, passing through pts.
+// NewPolyline panics if no points are provided.
+func NewPolyline(width float64, pts ...FPoint) *Polyline {
+	if len(pts) < 1 {
+		panic("a polyline must have at least 1 point")
+	}
+	radius := width / 2
+	box := boundingBox(pts)
+	box.min.X -= radius
+	box.min.Y -= radius
+	box.max.X += radius
+	box.max.Y += radius
+	return &Polyline{pts: pts, radius: radius, box: box}
+}
+
+// Overlaps reports whether the polyline intersects the interior of r.
+func (l *Polyline) Overlaps(r image.Rectangle) bool {
+	fr := toFRect(r)
+	if !l.box.overlaps(fr) {
+		return false
+	}
+	for i := 0; i < l.numSegments(); i++ {
+		a, b := l.segment(i)
+		if fr.segmentDist(a, b) < l.radius {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether r lies entirely inside the polyline.
+//
+// Only the segments are considered one by one, as a consequence Covers
+// returns false for a rectangle covered by the union of many segments but by
+// none of them individually.
+func (l *Polyline) Covers(r image.Rectangle) bool {
+	fr := toFRect(r)
+	if !l.box.covers(fr) {
+		return false
+	}
+	corners := fr.corners()
+	for i := 0; i < l.numSegments(); i++ {
+		a, b := l.segment(i)
+		covered := true
+		for _, c := range corners {
+			if pointSegmentDist(c, a, b) > l.radius {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// numSegments returns the number of segments of the polyline, a single point
+// being considered as a null-length segment.
+func (l *Polyline) numSegments() int {
+	if len(l.pts) == 1 {
+		return 1
+	}
+	return len(l.pts) - 1
+}
+
+// segment returns the end points of the ith segment.
+func (l *Polyline) segment(i int) (FPoint, FPoint) {
+	if len(l.pts) == 1 {
+		return l.pts[0], l.pts[0]
+	}
+	return l.pts[i], l.pts[i+1]
+}
+
+// frect is a rectangle with floating-point coordinates.
+type frect struct {
+	min, max FPoint
+}
+
+func toFRect(r image.Rectangle) frect {
+	return frect{fpoint(r.Min), fpoint(r.Max)}
+}
+
+func boundingBox(pts []FPoint) frect {
+	box := frect{pts[0], pts[0]}
+	for _, pt := range pts[1:] {
+		box.min.X = math.Min(box.min.X, pt.X)
+		box.min.Y = math.Min(box.min.Y, pt.Y)
+		box.max.X = math.Max(box.max.X, pt.X)
+		box.max.Y = math.Max(box.max.Y, pt.Y)
+	}
+	return box
+}
+
+func (r frect) center() FPoint {
+	return FPoint{(r.min.X + r.max.X) / 2, (r.min.Y + r.max.Y) / 2}
+}
+
+func (r frect) corners() [4]FPoint {
+	return [4]FPoint{
+		r.min,
+		{r.max.X, r.min.Y},
+		{r.min.X, r.max.Y},
+		r.max,
+	}
+}
+
+// overlaps reports whether r and the interior of s intersect.
+func (r frect) overlaps(s frect) bool {
+	return r.min.X < s.max.X && s.min.X < r.max.X &&
+		r.min.Y < s.max.Y && s.min.Y < r.max.Y
+}
+
+// covers reports whether s lies inside r.
+func (r frect) covers(s frect) bool {
+	return r.min.X <= s.min.X && s.max.X <= r.max.X &&
+		r.min.Y <= s.min.Y && s.max.Y <= r.max.Y
+}
+
+// strictlyContains reports whether pt lies in the interior of r.
+func (r frect) strictlyContains(pt FPoint) bool {
+	return r.min.X < pt.X && pt.X < r.max.X &&
+		r.min.Y < pt.Y && pt.Y < r.max.Y
+}
+
+// clip clips the segment [a,b] to r, following the Liang-Barsky algorithm.
+// It returns the parametric interval [t0,t1] of the segment that lies in r,
+// ok is false if the segment doesn't intersect r.
+func (r frect) clip(a, b FPoint) (t0, t1 float64, ok bool) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	p := [4]float64{-dx, dx, -dy, dy}
+	q := [4]float64{a.X - r.min.X, r.max.X - a.X, a.Y - r.min.Y, r.max.Y - a.Y}
+	t0, t1 = 0, 1
+	for i := range p {
+		if p[i] == 0 {
+			if q[i] < 0 {
+				return 0, 0, false
+			}
+			continue
+		}
+		t := q[i] / p[i]
+		if p[i] < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+		if t0 > t1 {
+			return 0, 0, false
+		}
+	}
+	return t0, t1, true
+}
+
+// crossedBy reports whether the segment [a,b] intersects the interior of r.
+func (r frect) crossedBy(a, b FPoint) bool {
+	t0, t1, ok := r.clip(a, b)
+	if !ok {
+		return false
+	}
+	// A segment clipped to a convex area either lies entirely on its border
+	// or has its middle point in the interior.
+	t := (t0 + t1) / 2
+	return r.strictlyContains(FPoint{a.X + t*(b.X-a.X), a.Y + t*(b.Y-a.Y)})
+}
+
+// segmentDist returns the distance between r and the segment [a,b].
+func (r frect) segmentDist(a, b FPoint) float64 {
+	if _, _, ok := r.clip(a, b); ok {
+		return 0
+	}
+	// The closest points of 2 disjoint convex shapes can always be found on a
+	// vertex of one of them.
+	d := math.Min(r.pointDist(a), r.pointDist(b))
+	for _, c := range r.corners() {
+		d = math.Min(d, pointSegmentDist(c, a, b))
+	}
+	return d
+}
+
+// pointDist returns the distance between r and pt.
+func (r frect) pointDist(pt FPoint) float64 {
+	dx := math.Max(0, math.Max(r.min.X-pt.X, pt.X-r.max.X))
+	dy := math.Max(0, math.Max(r.min.Y-pt.Y, pt.Y-r.max.Y))
+	return math.Hypot(dx, dy)
+}
+
+// pointSegmentDist returns the distance between pt and the segment [a,b].
+func pointSegmentDist(pt, a, b FPoint) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return math.Hypot(pt.X-a.X, pt.Y-a.Y)
+	}
+	t := ((pt.X-a.X)*dx + (pt.Y-a.Y)*dy) / l2
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(pt.X-(a.X+t*dx), pt.Y-(a.Y+t*dy))
+}
@@ -1,5 +1,5 @@
 module github.com/arl/go-rquad
 
-go 1.12
+go 1.13
 
 require github.com/arl/imgtools v0.1.0
@@ -0,0 +1,69 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+
+	"github.com/arl/go-rquad/internal"
+	"github.com/arl/imgtools/imgscan"
+)
+
+// boundsOf returns the bounds of n, or an empty rectangle if n is nil.
+func boundsOf(n *CNNode) image.Rectangle {
+	if n == nil {
+		return image.Rectangle{}
+	}
+	return n.bounds
+}
+
+// loadTree creates a quadtree of the given implementation out of a png file.
+func loadTree(t *testing.T, fn string, opts ...Option) Quadtree {
+	t.Helper()
+	bm, err := internal.LoadPNG(fn)
+	check(t, err)
+	scanner, err := imgscan.NewScanner(bm)
+	check(t, err)
+	q, err := New(scanner, opts...)
+	check(t, err)
+	return q
+}
+
+// checkNeighbours checks that for each leaf of q, ForEachNeighbour returns
+// the same nodes as the generic neighbour finding technique.
+func checkNeighbours(t *testing.T, q Quadtree) {
+	t.Helper()
+	q.ForEachLeaf(Gray, func(n Node) {
+		want := make(map[Node]bool)
+		for _, dir := range []Side{West, North, East, South} {
+			neighbours(n, dir, func(nb Node) { want[nb] = true })
+		}
+		got := make(map[Node]bool)
+		ForEachNeighbour(n, func(nb Node) { got[nb] = true })
+		if len(got) != len(want) {
+			t.Fatalf("leaf %v: got %d neighbours, want %d", n.Bounds(), len(got), len(want))
+		}
+		for nb := range want {
+			if !got[nb] {
+				t.Fatalf("leaf %v: neighbour %v not found", n.Bounds(), nb.Bounds())
+			}
+		}
+	})
+}
+
+// checkCompact checks that no Gray node of the subtree rooted at n has 4 leaf
+// children of the same color.
+func checkCompact(t *testing.T, n Node) {
+	t.Helper()
+	if n.Color() != Gray {
+		return
+	}
+	col := n.Child(Northwest).Color()
+	same := col != Gray
+	for q := Northwest; q <= Southeast; q++ {
+		same = same && n.Child(q).Color() == col
+		checkCompact(t, n.Child(q))
+	}
+	if same {
+		t.Fatalf("node %v has 4 %v leaf children", n.Bounds(), col)
+	}
+}
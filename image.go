@@ -0,0 +1,208 @@
+package rquad
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+// ColorModel returns the binimg.Model color model.
+func (q *BasicTree) ColorModel() color.Model {
+	return binimg.Model
+}
+
+// Bounds returns the bounds of the quadtree root node.
+func (q *BasicTree) Bounds() image.Rectangle {
+	return q.root.Bounds()
+}
+
+// At returns the color of the pixel at (x, y), that is binimg.Black or
+// binimg.White depending on the color of the leaf containing it, or the zero
+// color if (x, y) lies outside of the quadtree.
+//
+// With ColorModel and Bounds, At allows BasicTree to be used as an
+// image.Image, without rendering it first.
+func (q *BasicTree) At(x, y int) color.Color {
+	return leafColor(Locate(q, image.Pt(x, y)))
+}
+
+// Set sets the color of the pixel at (x, y), c being converted to Black or
+// White by binimg.Model, so that BasicTree implements draw.Image.
+//
+// The leaf containing the pixel is subdivided down to the resolution limit,
+// set by the options used when creating the quadtree, so at low resolution,
+// setting a pixel sets the color of a whole leaf. Leaves are then merged, up
+// from the modified leaf, as long as they have 4 leaf siblings of the same
+// color.
+func (q *BasicTree) Set(x, y int, c color.Color) {
+	top, removed := setColor(Locate(q, image.Pt(x, y)), image.Pt(x, y), bitColor(c), q.opts, splitBasicNode)
+	if top != nil {
+		q.updateLeaves(removed, top)
+	}
+}
+
+// At returns the color of the pixel at (x, y), that is binimg.Black or
+// binimg.White depending on the color of the leaf containing it, or the zero
+// color if (x, y) lies outside of the quadtree.
+//
+// With ColorModel and Bounds, At allows CNTree to be used as an image.Image,
+// without rendering it first.
+func (q *CNTree) At(x, y int) color.Color {
+	return leafColor(q.locate(image.Pt(x, y)))
+}
+
+// Set sets the color of the pixel at (x, y), c being converted to Black or
+// White by binimg.Model, so that CNTree implements draw.Image.
+//
+// See BasicTree.Set for details. The cardinal neighbours of the modified
+// leaves, and of the leaves surrounding them, are updated accordingly.
+func (q *CNTree) Set(x, y int, c color.Color) {
+	top, removed := setColor(q.locate(image.Pt(x, y)), image.Pt(x, y), bitColor(c), q.opts, splitCNNode)
+	if top == nil {
+		return
+	}
+	q.updateLeaves(removed, top)
+
+	// locate requires nLevels to account for the smallest leaf
+	relink := collectLeaves(top, nil)
+	size := q.root.(*CNNode).size
+	for _, n := range relink {
+		for size>>(q.nLevels-1) > n.(*CNNode).size {
+			q.nLevels++
+		}
+	}
+
+	for dir := West; dir <= South; dir++ {
+		neighbours(top, dir, func(n Node) {
+			relink = append(relink, n)
+		})
+	}
+	for _, n := range relink {
+		n.(*CNNode).findCardinalNeighbours()
+	}
+}
+
+// leafColor returns the color of the pixels of the leaf n, or the zero color
+// if n is nil.
+func leafColor(n Node) color.Color {
+	if n == nil {
+		return binimg.Bit{}
+	}
+	return bit(n.Color())
+}
+
+// bit converts the leaf color c to binimg.Black or binimg.White.
+func bit(c Color) binimg.Bit {
+	if c == White {
+		return binimg.White
+	}
+	return binimg.Black
+}
+
+// bitColor converts c to Black or White, following binimg.Model.
+func bitColor(c color.Color) Color {
+	if binimg.Model.Convert(c) == binimg.White {
+		return White
+	}
+	return Black
+}
+
+// setColor sets to c the color of the pixel pt, contained in the leaf n. It
+// returns the root of the modified subtree, or nil if nothing has been
+// modified, and the nodes that may have been leaves before the modification
+// but aren't anymore, that is n and the children of the merged nodes.
+//
+// split subdivides a leaf into 4 leaves of the same color.
+func setColor(n Node, pt image.Point, c Color, o options, split func(Node)) (top Node, removed []Node) {
+	if n == nil || n.Color() == c {
+		return nil, nil
+	}
+	removed = append(removed, n)
+
+	// subdivide down to the resolution limit
+	top = n
+	depth := Depth(n)
+	topDepth := depth
+	for ; o.canSubdivide(n.Bounds(), depth); depth++ {
+		split(n)
+		for q := Northwest; q <= Southeast; q++ {
+			if pt.In(n.Child(q).Bounds()) {
+				n = n.Child(q)
+				break
+			}
+		}
+	}
+	n.(basicNoder).basicNode().color = c
+
+	// merge up
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		depth--
+		bp := p.(basicNoder).basicNode()
+		children := bp.c
+		compactNode(bp)
+		if p.Color() == Gray {
+			break
+		}
+		removed = append(removed, children[:]...)
+		if depth < topDepth {
+			top, topDepth = p, depth
+		}
+	}
+	return top, removed
+}
+
+// updateLeaves updates the leaves of the quadtree after the modification of
+// the subtree rooted at top: the nodes of removed are removed from the
+// leaves, and the leaves of top are added and indexed.
+func (q *BasicTree) updateLeaves(removed []Node, top Node) {
+	for _, n := range removed {
+		i := n.(basicNoder).basicNode().index
+		if i >= len(q.leaves) || q.leaves[i] != n {
+			// n wasn't a leaf
+			continue
+		}
+		// move the last leaf in place of n
+		last := len(q.leaves) - 1
+		q.leaves[i] = q.leaves[last]
+		q.leaves[i].(basicNoder).basicNode().index = i
+		q.leaves[last] = nil
+		q.leaves = q.leaves[:last]
+	}
+	from := len(q.leaves)
+	q.leaves = collectLeaves(top, q.leaves)
+	indexLeaves(q.leaves, from)
+}
+
+// splitBasicNode turns the leaf n into a Gray node having 4 leaf children of
+// the color of n.
+func splitBasicNode(n Node) {
+	bn := n.(*BasicNode)
+	for q, qb := range quadrants(bn.bounds) {
+		bn.c[q] = &BasicNode{
+			parent:   bn,
+			bounds:   qb,
+			color:    bn.color,
+			location: Quadrant(q),
+		}
+	}
+	bn.color = Gray
+}
+
+// splitCNNode turns the leaf n into a Gray node having 4 leaf children of the
+// color of n. The cardinal neighbours are not set.
+func splitCNNode(n Node) {
+	cn := n.(*CNNode)
+	for q, qb := range quadrants(cn.bounds) {
+		cn.c[q] = &CNNode{
+			BasicNode: BasicNode{
+				parent:   cn,
+				bounds:   qb,
+				color:    cn.color,
+				location: Quadrant(q),
+			},
+			size: qb.Dx(),
+		}
+	}
+	cn.color = Gray
+}
@@ -0,0 +1,134 @@
+package rquad
+
+import (
+	"image"
+	"image/draw"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+var (
+	_ draw.Image = (*BasicTree)(nil)
+	_ draw.Image = (*CNTree)(nil)
+)
+
+// checkImage checks that q and img have the same pixels, and that q is
+// consistent.
+func checkImage(t *testing.T, q Quadtree, img image.Image) {
+	t.Helper()
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if got, want := q.(image.Image).At(x, y), img.At(x, y); got != want {
+				t.Fatalf("pixel (%d,%d) is %v, want %v", x, y, got, want)
+			}
+			if got, want := Locate(q, image.Pt(x, y)), pointLocation(q.Root(), image.Pt(x, y)); got != want {
+				t.Fatalf("Locate(%d,%d) = %v, want %v", x, y, got.Bounds(), want.Bounds())
+			}
+		}
+	}
+	if area := Area(q, Gray); area != b.Dx()*b.Dy() {
+		t.Fatalf("leaves cover %d pixels, want %d", area, b.Dx()*b.Dy())
+	}
+	checkCompact(t, q.Root())
+	checkNeighbours(t, q)
+
+	// the leaves are kept up to date by Set
+	want := collectLeaves(q.Root(), nil)
+	var got []Node
+	q.ForEachLeaf(Gray, func(n Node) { got = append(got, n) })
+	if len(got) != len(want) {
+		t.Fatalf("ForEachLeaf gives %d leaves, want %d", len(got), len(want))
+	}
+	for _, n := range want {
+		if !containsNode(got, n) {
+			t.Fatalf("leaf %v not given by ForEachLeaf", n.Bounds())
+		}
+	}
+}
+
+func TestImage(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth3.32x32.png", WithImplementation(impl))
+		img := q.(image.Image)
+		if img.Bounds() != image.Rect(0, 0, 32, 32) {
+			t.Errorf("got bounds %v", img.Bounds())
+		}
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				want := binimg.Black
+				if pixelColor(q, image.Pt(x, y)) == White {
+					want = binimg.White
+				}
+				if got := img.At(x, y); got != want {
+					t.Fatalf("At(%d, %d) = %v, want %v", x, y, got, want)
+				}
+			}
+		}
+		if got := img.At(32, 0); got != (binimg.Bit{}) {
+			t.Errorf("At outside of bounds = %v, want zero color", got)
+		}
+
+		// converting the quadtree to a bitmap
+		bm := binimg.NewFromImage(img)
+		checkImage(t, q, bm)
+	}
+}
+
+func TestDrawImage(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth2.32x32.png", WithImplementation(impl))
+		for _, q := range []Quadtree{q, Complement(q)} {
+			dst := q.(draw.Image)
+			ref := binimg.NewFromImage(dst)
+
+			// rectangles
+			r := rand.New(rand.NewSource(99))
+			for i := 0; i < 20; i++ {
+				x, y := r.Intn(32), r.Intn(32)
+				rect := image.Rect(x, y, x+r.Intn(12), y+r.Intn(12))
+				src := image.NewUniform(binimg.Black)
+				if i%2 == 0 {
+					src = image.NewUniform(binimg.White)
+				}
+				draw.Draw(dst, rect, src, image.Point{}, draw.Src)
+				draw.Draw(ref, rect, src, image.Point{}, draw.Src)
+				checkImage(t, q, ref)
+			}
+
+			// single pixels
+			for i := 0; i < 200; i++ {
+				x, y := r.Intn(32), r.Intn(32)
+				c := binimg.Bit{V: byte(r.Intn(2) * 255)}
+				dst.Set(x, y, c)
+				ref.Set(x, y, c)
+			}
+			checkImage(t, q, ref)
+
+			// filling the whole image merges all leaves
+			draw.Draw(dst, dst.Bounds(), image.NewUniform(binimg.White), image.Point{}, draw.Src)
+			if q.Root().Color() != White {
+				t.Errorf("root should be a white leaf, got %v", q.Root().Color())
+			}
+		}
+	}
+}
+
+func TestSetResolution(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth1.32x32.png", WithImplementation(impl), WithResolution(4))
+		dst := q.(draw.Image)
+		var pt image.Point
+		q.ForEachLeaf(White, func(n Node) {
+			pt = n.Bounds().Min
+		})
+		dst.Set(pt.X, pt.Y, binimg.Black)
+		n := Locate(q, pt)
+		if n.Color() != Black || n.Bounds().Dx() < 4 || n.Bounds().Dy() < 4 {
+			t.Errorf("got leaf %v of color %v, want a black leaf of at least 4x4", n.Bounds(), n.Color())
+		}
+		checkNeighbours(t, q)
+	}
+}
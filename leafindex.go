@@ -0,0 +1,67 @@
+package rquad
+
+// NumLeaves returns the number of leaves of the quadtree.
+func (q *BasicTree) NumLeaves() int {
+	return len(q.leaves)
+}
+
+// LeafByIndex returns the leaf of index i, which must lie in
+// [0, NumLeaves()).
+//
+// Leaves are densely indexed from 0 to NumLeaves()-1, so that per-leaf
+// state can be stored in slices rather than in maps keyed by Node. Indices
+// are stable as long as the quadtree is not modified, by Set or Compact for
+// example.
+func (q *BasicTree) LeafByIndex(i int) Node {
+	return q.leaves[i]
+}
+
+// IndexOf returns the index of the leaf n. ok is false if n is not a leaf of
+// the quadtree.
+//
+// Leaves are indexed as they are added to the quadtree, so IndexOf runs in
+// constant time and doesn't modify the quadtree: it can be called
+// concurrently with other read-only methods.
+func (q *BasicTree) IndexOf(n Node) (i int, ok bool) {
+	bn, ok := n.(basicNoder)
+	if !ok {
+		return -1, false
+	}
+	i = bn.basicNode().index
+	if i >= len(q.leaves) || q.leaves[i] != n {
+		return -1, false
+	}
+	return i, true
+}
+
+// LeafGraph is the adjacency graph of the leaves of a quadtree, stored in
+// compressed sparse row (CSR) format: the indices of the neighbours of leaf i
+// are Indices[Offsets[i]:Offsets[i+1]].
+type LeafGraph struct {
+	Offsets []int // offset of the neighbours of each leaf, plus the total
+	Indices []int // concatenated neighbours indices
+}
+
+// Neighbours returns the indices of the neighbours of leaf i.
+func (g *LeafGraph) Neighbours(i int) []int {
+	return g.Indices[g.Offsets[i]:g.Offsets[i+1]]
+}
+
+// LeafGraph returns the adjacency graph of the leaves of the quadtree, built
+// from ForEachNeighbour and indexed as LeafByIndex. Storing the graph in 2
+// contiguous slices allows cache-efficient graph algorithms.
+//
+// The graph is a snapshot, it's not updated when the quadtree is modified.
+func (q *BasicTree) LeafGraph() *LeafGraph {
+	leaves := q.leaves
+	g := &LeafGraph{Offsets: make([]int, 0, len(leaves)+1)}
+	for _, n := range leaves {
+		g.Offsets = append(g.Offsets, len(g.Indices))
+		ForEachNeighbour(n, func(nb Node) {
+			i, _ := q.IndexOf(nb)
+			g.Indices = append(g.Indices, i)
+		})
+	}
+	g.Offsets = append(g.Offsets, len(g.Indices))
+	return g
+}
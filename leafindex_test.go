@@ -0,0 +1,116 @@
+package rquad
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+// indexedTree is implemented by BasicTree and CNTree.
+type indexedTree interface {
+	Quadtree
+	NumLeaves() int
+	LeafByIndex(int) Node
+	IndexOf(Node) (int, bool)
+	LeafGraph() *LeafGraph
+	Set(int, int, color.Color)
+}
+
+func checkLeafIndex(t *testing.T, q indexedTree) {
+	t.Helper()
+	var count int
+	q.ForEachLeaf(Gray, func(Node) { count++ })
+	if q.NumLeaves() != count {
+		t.Fatalf("NumLeaves() = %d, want %d", q.NumLeaves(), count)
+	}
+	for i := 0; i < q.NumLeaves(); i++ {
+		n := q.LeafByIndex(i)
+		if n.Color() == Gray {
+			t.Fatalf("LeafByIndex(%d) is Gray", i)
+		}
+		if j, ok := q.IndexOf(n); !ok || j != i {
+			t.Fatalf("IndexOf(LeafByIndex(%d)) = %d, %t", i, j, ok)
+		}
+	}
+}
+
+func TestLeafIndex(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth2.32x32.png", WithImplementation(impl)).(indexedTree)
+		checkLeafIndex(t, q)
+
+		if _, ok := q.IndexOf(q.Root()); ok {
+			t.Errorf("IndexOf should return false for a Gray node")
+		}
+		other := loadTree(t, "./testdata/labyrinth2.32x32.png", WithImplementation(impl))
+		if _, ok := q.IndexOf(Locate(other, image.Pt(0, 0))); ok {
+			t.Errorf("IndexOf should return false for a leaf of another quadtree")
+		}
+
+		// modifying the quadtree
+		for i := 0; i < 16; i++ {
+			q.Set(i, i, binimg.White)
+		}
+		checkLeafIndex(t, q)
+	}
+}
+
+func TestIndexOfConcurrent(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth3.32x32.png", WithImplementation(impl)).(indexedTree)
+		q.Set(3, 5, binimg.White)
+		q.Set(20, 7, binimg.Black)
+
+		// IndexOf only reads the quadtree, this is checked by the race detector
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				q.ForEachLeaf(Gray, func(n Node) {
+					if i, ok := q.IndexOf(n); !ok || q.LeafByIndex(i) != n {
+						t.Errorf("IndexOf(%v) = %d, %t", n.Bounds(), i, ok)
+					}
+				})
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func TestLeafGraph(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth3.32x32.png", WithImplementation(impl)).(indexedTree)
+		g := q.LeafGraph()
+		if len(g.Offsets) != q.NumLeaves()+1 || g.Offsets[len(g.Offsets)-1] != len(g.Indices) {
+			t.Fatalf("got %d offsets for %d leaves and %d indices", len(g.Offsets), q.NumLeaves(), len(g.Indices))
+		}
+		for i := 0; i < q.NumLeaves(); i++ {
+			want := make(map[int]bool)
+			ForEachNeighbour(q.LeafByIndex(i), func(n Node) {
+				j, _ := q.IndexOf(n)
+				want[j] = true
+			})
+			got := g.Neighbours(i)
+			if len(got) != len(want) {
+				t.Fatalf("leaf %d has %d neighbours, want %d", i, len(got), len(want))
+			}
+			for _, j := range got {
+				if !want[j] {
+					t.Fatalf("leaf %d has unexpected neighbour %d", i, j)
+				}
+				// adjacency is symmetric
+				found := false
+				for _, k := range g.Neighbours(j) {
+					found = found || k == i
+				}
+				if !found {
+					t.Fatalf("leaf %d is a neighbour of %d, but not the reverse", j, i)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package rquad
+
+import "errors"
+
+// ErrLevel is returned by level-of-detail functions when the requested level
+// is negative.
+var ErrLevel = errors.New("level must be greater or equal to 0")
+
+// Depth returns the depth of n, that is its number of ancestors, the root
+// lying at depth 0.
+func Depth(n Node) int {
+	depth := 0
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		depth++
+	}
+	return depth
+}
+
+// ForEachNodeAtLevel calls fn for each node of q lying at the given depth,
+// Gray nodes included, in Z-order. Leaves lying at a lower depth are not
+// considered. ForEachNodeAtLevel returns ErrLevel, without calling fn, if
+// level is negative.
+func ForEachNodeAtLevel(q Quadtree, level int, fn func(Node)) error {
+	if level < 0 {
+		return ErrLevel
+	}
+	forEachNodeAtLevel(q.Root(), level, fn)
+	return nil
+}
+
+func forEachNodeAtLevel(n Node, level int, fn func(Node)) {
+	switch {
+	case level == 0:
+		fn(n)
+	case level > 0 && n.Color() == Gray:
+		for q := Northwest; q <= Southeast; q++ {
+			forEachNodeAtLevel(n.Child(q), level-1, fn)
+		}
+	}
+}
+
+// ForEachLODLeaf calls fn for each leaf of q truncated at the given depth,
+// in Z-order. That is, for each leaf lying at a lower depth, and for each
+// node lying at that depth, considered as a leaf. The color passed to fn is
+// the color of the node or, for Gray nodes, the aggregated color of their
+// descendant leaves, following policy.
+//
+// This allows to consider a quadtree at a coarser level of detail without
+// creating a new one. See Truncate to create one. ForEachLODLeaf returns
+// ErrLevel, without calling fn, if level is negative.
+func ForEachLODLeaf(q Quadtree, level int, policy CoarsenPolicy, fn func(Node, Color)) error {
+	if level < 0 {
+		return ErrLevel
+	}
+	forEachLODLeaf(q.Root(), level, policy, fn)
+	return nil
+}
+
+func forEachLODLeaf(n Node, level int, policy CoarsenPolicy, fn func(Node, Color)) {
+	switch {
+	case n.Color() != Gray:
+		fn(n, n.Color())
+	case level == 0:
+		fn(n, policyColor(n, policy))
+	default:
+		for q := Northwest; q <= Southeast; q++ {
+			forEachLODLeaf(n.Child(q), level-1, policy, fn)
+		}
+	}
+}
+
+// Truncate returns a new quadtree, copy of q in which the Gray nodes lying at
+// the given depth are turned into leaves, the color of which is decided by
+// policy.
+//
+// The returned quadtree has the same implementation and creation options as
+// q, its maximum depth being limited to level. Truncate returns ErrLevel if
+// level is negative.
+func Truncate(q Quadtree, level int, policy CoarsenPolicy) (Quadtree, error) {
+	if level < 0 {
+		return nil, ErrLevel
+	}
+	o := optionsOf(q)
+	if level < o.maxDepth {
+		o.maxDepth = level
+	}
+	root := coarsenNode(q.Root(), nil, 0, o, policy)
+	return newTree(implementationOf(q), root, o), nil
+}
@@ -0,0 +1,105 @@
+package rquad
+
+import (
+	"fmt"
+	"image"
+	"testing"
+)
+
+func TestDepth(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth3.32x32.png", WithImplementation(CardinalNeighbour))
+	if d := Depth(q.Root()); d != 0 {
+		t.Errorf("root depth = %d, want 0", d)
+	}
+	q.ForEachLeaf(Gray, func(n Node) {
+		if want := 32 >> uint(Depth(n)); n.Bounds().Dx() != want {
+			t.Fatalf("leaf %v has depth %d", n.Bounds(), Depth(n))
+		}
+	})
+}
+
+func TestForEachNodeAtLevel(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth2.32x32.png", WithImplementation(impl))
+		for level := 0; level <= treeDepth(q.Root())+1; level++ {
+			area, gray := 0, false
+			err := ForEachNodeAtLevel(q, level, func(n Node) {
+				if Depth(n) != level {
+					t.Fatalf("node %v lies at depth %d, want %d", n.Bounds(), Depth(n), level)
+				}
+				gray = gray || n.Color() == Gray
+				area += n.Bounds().Dx() * n.Bounds().Dy()
+			})
+			check(t, err)
+			// nodes at the level, and shallower leaves, cover the whole area
+			q.ForEachLeaf(Gray, func(n Node) {
+				if Depth(n) < level {
+					area += n.Bounds().Dx() * n.Bounds().Dy()
+				}
+			})
+			if area != 32*32 {
+				t.Errorf("level %d: nodes cover %d pixels, want %d", level, area, 32*32)
+			}
+			if level < treeDepth(q.Root()) && !gray {
+				t.Errorf("level %d: Gray nodes should be visited", level)
+			}
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth3.32x32.png", WithImplementation(impl))
+		for level := 0; level <= 5; level++ {
+			for _, policy := range []CoarsenPolicy{AnyBlack, Majority, AnyWhite} {
+				t.Run(fmt.Sprintf("%v/level=%d/policy=%d", impl, level, policy), func(t *testing.T) {
+					tq, err := Truncate(q, level, policy)
+					check(t, err)
+					if d := treeDepth(tq.Root()); d > level {
+						t.Fatalf("truncated quadtree has depth %d", d)
+					}
+					checkCompact(t, tq.Root())
+					checkNeighbours(t, tq)
+
+					// ForEachLODLeaf sees the same pixels as Truncate
+					area := 0
+					err = ForEachLODLeaf(q, level, policy, func(n Node, c Color) {
+						if Depth(n) > level || (n.Color() != Gray && n.Color() != c) {
+							t.Fatalf("node %v of depth %d and color %v has LOD color %v", n.Bounds(), Depth(n), n.Color(), c)
+						}
+						b := n.Bounds()
+						area += b.Dx() * b.Dy()
+						for y := b.Min.Y; y < b.Max.Y; y++ {
+							for x := b.Min.X; x < b.Max.X; x++ {
+								if got := pixelColor(tq, image.Pt(x, y)); got != c {
+									t.Fatalf("pixel (%d,%d) is %v, want %v", x, y, got, c)
+								}
+							}
+						}
+					})
+					check(t, err)
+					if area != 32*32 {
+						t.Fatalf("LOD leaves cover %d pixels, want %d", area, 32*32)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestNegativeLevel(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth3.32x32.png")
+	if _, err := Truncate(q, -1, Majority); err != ErrLevel {
+		t.Errorf("Truncate: got error %v, want %v", err, ErrLevel)
+	}
+	called := false
+	if err := ForEachNodeAtLevel(q, -1, func(Node) { called = true }); err != ErrLevel {
+		t.Errorf("ForEachNodeAtLevel: got error %v, want %v", err, ErrLevel)
+	}
+	if err := ForEachLODLeaf(q, -1, Majority, func(Node, Color) { called = true }); err != ErrLevel {
+		t.Errorf("ForEachLODLeaf: got error %v, want %v", err, ErrLevel)
+	}
+	if called {
+		t.Errorf("fn should not be called for a negative level")
+	}
+}
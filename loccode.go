@@ -0,0 +1,111 @@
+package rquad
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrLocationalCode is returned when parsing an invalid quadrant path.
+var ErrLocationalCode = errors.New("invalid locational code")
+
+// MaxCodeLevel is the maximum depth of a node that can be identified by a
+// LocationalCode.
+const MaxCodeLevel = 32
+
+// LocationalCode identifies a node by its position in the quadtree
+// hierarchy, it doesn't depend on the quadtree implementation, and remains
+// valid across processes or serializations of the same quadtree.
+//
+// Morton holds the quadrants of the path going from the root down to the
+// node, 2 bits per level, the quadrant of the deepest level being stored in
+// the least significant bits. As the Quadrant values follow the Z-order,
+// the even bits of Morton are the bits of the column of the node among the
+// 2^Level columns of its level, and the odd bits are those of its row, that
+// is Morton is the Morton code of the node at its level.
+type LocationalCode struct {
+	Morton uint64 // interleaved quadrant path
+	Level  int    // depth of the node, 0 for the root
+}
+
+// Code returns the locational code of n. Code panics if n lies deeper than
+// MaxCodeLevel.
+func Code(n Node) LocationalCode {
+	var c LocationalCode
+	for ; n.Parent() != nil; n = n.Parent() {
+		if c.Level == MaxCodeLevel {
+			panic("node is too deep to have a locational code")
+		}
+		c.Morton |= uint64(n.Location()) << uint(2*c.Level)
+		c.Level++
+	}
+	return c
+}
+
+// NodeByCode returns the node of q identified by code, or nil if there's no
+// such node.
+func NodeByCode(q Quadtree, code LocationalCode) Node {
+	if code.Level < 0 || code.Level > MaxCodeLevel {
+		return nil
+	}
+	n := q.Root()
+	for _, quad := range code.Path() {
+		if n.Color() != Gray {
+			return nil
+		}
+		n = n.Child(quad)
+	}
+	return n
+}
+
+// Path returns the quadrants of the path going from the root down to the
+// node identified by c.
+func (c LocationalCode) Path() []Quadrant {
+	path := make([]Quadrant, c.Level)
+	for i := range path {
+		path[i] = Quadrant(c.Morton >> uint(2*(c.Level-1-i)) & 3)
+	}
+	return path
+}
+
+// abbreviations of the quadrants, as used in quadrant path strings.
+var quadrantAbbr = [4]string{
+	Northwest: "NW",
+	Northeast: "NE",
+	Southwest: "SW",
+	Southeast: "SE",
+}
+
+// String returns the quadrant path of c, quadrants being separated by
+// slashes, for example "NW/SE/NE". The path of the root is the empty string.
+func (c LocationalCode) String() string {
+	path := c.Path()
+	names := make([]string, len(path))
+	for i, q := range path {
+		names[i] = quadrantAbbr[q]
+	}
+	return strings.Join(names, "/")
+}
+
+// ParseLocationalCode parses a quadrant path, as returned by
+// LocationalCode.String, and returns the corresponding locational code.
+// Quadrant abbreviations are case-insensitive.
+func ParseLocationalCode(s string) (LocationalCode, error) {
+	var c LocationalCode
+	if s == "" {
+		return c, nil
+	}
+	for _, name := range strings.Split(s, "/") {
+		quad := -1
+		for q, abbr := range quadrantAbbr {
+			if strings.EqualFold(name, abbr) {
+				quad = q
+			}
+		}
+		if quad == -1 || c.Level == MaxCodeLevel {
+			return LocationalCode{}, ErrLocationalCode
+		}
+		c.Morton = c.Morton<<2 | uint64(quad)
+		c.Level++
+	}
+	return c, nil
+}
@@ -0,0 +1,97 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+)
+
+// allNodes returns all the nodes of the subtree rooted at n, Gray ones
+// included.
+func allNodes(n Node) []Node {
+	nodes := []Node{n}
+	if n.Color() == Gray {
+		for q := Northwest; q <= Southeast; q++ {
+			nodes = append(nodes, allNodes(n.Child(q))...)
+		}
+	}
+	return nodes
+}
+
+// deinterleave returns the even bits of x.
+func deinterleave(x uint64) int {
+	var v int
+	for i := uint(0); i < 32; i++ {
+		v |= int(x>>(2*i)&1) << i
+	}
+	return v
+}
+
+func TestLocationalCode(t *testing.T) {
+	basic := loadTree(t, "./testdata/labyrinth3.32x32.png")
+	cn := loadTree(t, "./testdata/labyrinth3.32x32.png", WithImplementation(CardinalNeighbour))
+	for _, n := range allNodes(basic.Root()) {
+		code := Code(n)
+		if code.Level != Depth(n) {
+			t.Fatalf("node %v: got level %d, want %d", n.Bounds(), code.Level, Depth(n))
+		}
+		if got := NodeByCode(basic, code); got != n {
+			t.Fatalf("NodeByCode(%v) returned another node", code)
+		}
+
+		// same node in the other implementation
+		other := NodeByCode(cn, code)
+		if other == nil || other.Bounds() != n.Bounds() || other.Color() != n.Color() || Code(other) != code {
+			t.Fatalf("NodeByCode(%v) doesn't return the same node on both implementations", code)
+		}
+
+		// Morton code gives the node position
+		size := 32 >> uint(code.Level)
+		pos := image.Pt(deinterleave(code.Morton), deinterleave(code.Morton>>1)).Mul(size)
+		if n.Bounds() != image.Rect(0, 0, size, size).Add(pos) {
+			t.Fatalf("node %v has Morton code %b at level %d", n.Bounds(), code.Morton, code.Level)
+		}
+
+		parsed, err := ParseLocationalCode(code.String())
+		check(t, err)
+		if parsed != code {
+			t.Fatalf("ParseLocationalCode(%q) = %v, want %v", code.String(), parsed, code)
+		}
+	}
+}
+
+func TestParseLocationalCode(t *testing.T) {
+	testTbl := []struct {
+		s       string
+		path    []Quadrant
+		wantErr error
+	}{
+		{"", nil, nil},
+		{"NW", []Quadrant{Northwest}, nil},
+		{"NW/SE/NE", []Quadrant{Northwest, Southeast, Northeast}, nil},
+		{"sw/Ne", []Quadrant{Southwest, Northeast}, nil},
+		{"NW/", nil, ErrLocationalCode},
+		{"NW/XX", nil, ErrLocationalCode},
+		{"/NW", nil, ErrLocationalCode},
+	}
+	for _, tt := range testTbl {
+		code, err := ParseLocationalCode(tt.s)
+		if err != tt.wantErr {
+			t.Errorf("ParseLocationalCode(%q): got error %v, want %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !equalPaths(code.Path(), tt.path) {
+			t.Errorf("ParseLocationalCode(%q).Path() = %v, want %v", tt.s, code.Path(), tt.path)
+		}
+	}
+
+	if got := (LocationalCode{Morton: 0x1e, Level: 3}).String(); got != "NE/SE/SW" {
+		t.Errorf("got %q, want \"NE/SE/SW\"", got)
+	}
+
+	q := loadTree(t, "./testdata/labyrinth1.32x32.png")
+	code, err := ParseLocationalCode("NW/NW/NW/NW/NW/NW/NW")
+	check(t, err)
+	if n := NodeByCode(q, code); n != nil {
+		t.Errorf("NodeByCode should return nil for a node deeper than the leaves, got %v", n.Bounds())
+	}
+}
@@ -0,0 +1,160 @@
+package rquad
+
+import "image"
+
+// Segment is a line segment going from A to B.
+type Segment struct {
+	A, B image.Point
+}
+
+// Len returns the length of the segment, which must be horizontal or
+// vertical.
+func (s Segment) Len() int {
+	d := s.B.Sub(s.A)
+	return abs(d.X) + abs(d.Y)
+}
+
+// Area returns the area, in square pixels, of the region of color c, that is
+// the sum of the areas of the leaves of color c. If c is Gray, Area returns
+// the area of the whole quadtree.
+func Area(q Quadtree, c Color) int {
+	area := 0
+	q.ForEachLeaf(c, func(n Node) {
+		b := n.Bounds()
+		area += b.Dx() * b.Dy()
+	})
+	return area
+}
+
+// Perimeter returns the length, in pixels, of the boundary of the region of
+// color c, which must be Black or White. The boundary is made of the edges
+// separating leaves of color c from leaves of the other color, and of the
+// edges of leaves of color c lying on the quadtree border.
+func Perimeter(q Quadtree, c Color) int {
+	perimeter := 0
+	forEachBoundary(q, c, func(s Segment) {
+		perimeter += s.Len()
+	})
+	return perimeter
+}
+
+// Boundary returns the segments forming the boundary of the region of color
+// c, which must be Black or White.
+//
+// The segments are oriented so that the region of color c lies on their
+// right, considering the y axis pointing downwards, as it does in images.
+// This makes the outer boundary of a region clockwise on screen. See
+// Perimeter for the definition of the boundary.
+func Boundary(q Quadtree, c Color) []Segment {
+	var segs []Segment
+	forEachBoundary(q, c, func(s Segment) {
+		segs = append(segs, s)
+	})
+	return segs
+}
+
+// forEachBoundary calls fn for each boundary segment of the region of color c.
+func forEachBoundary(q Quadtree, c Color, fn func(Segment)) {
+	border := q.Root().Bounds()
+	q.ForEachLeaf(c, func(n Node) {
+		b := n.Bounds()
+		ForEachNeighbour(n, func(nb Node) {
+			if nb.Color() != c {
+				if s, ok := sharedEdge(b, nb.Bounds()); ok {
+					fn(s)
+				}
+			}
+		})
+		// edges lying on the quadtree border
+		if b.Min.X == border.Min.X {
+			fn(edge(b, West))
+		}
+		if b.Min.Y == border.Min.Y {
+			fn(edge(b, North))
+		}
+		if b.Max.X == border.Max.X {
+			fn(edge(b, East))
+		}
+		if b.Max.Y == border.Max.Y {
+			fn(edge(b, South))
+		}
+	})
+}
+
+// edge returns the segment forming the side of r, oriented so that r lies on
+// its right.
+func edge(r image.Rectangle, side Side) Segment {
+	switch side {
+	case West:
+		return Segment{image.Pt(r.Min.X, r.Max.Y), r.Min}
+	case North:
+		return Segment{r.Min, image.Pt(r.Max.X, r.Min.Y)}
+	case East:
+		return Segment{image.Pt(r.Max.X, r.Min.Y), r.Max}
+	}
+	return Segment{r.Max, image.Pt(r.Min.X, r.Max.Y)}
+}
+
+// sharedEdge returns the segment shared by the adjacent rectangles a and b,
+// oriented so that a lies on its right. ok is false if a and b are not
+// adjacent.
+func sharedEdge(a, b image.Rectangle) (s Segment, ok bool) {
+	var side Side
+	switch {
+	case b.Max.X == a.Min.X:
+		side = West
+	case b.Max.Y == a.Min.Y:
+		side = North
+	case b.Min.X == a.Max.X:
+		side = East
+	case b.Min.Y == a.Max.Y:
+		side = South
+	default:
+		return Segment{}, false
+	}
+
+	// clip the side of a to the extent of b
+	s = edge(a, side)
+	switch side {
+	case West, East:
+		y0, y1 := max(a.Min.Y, b.Min.Y), min(a.Max.Y, b.Max.Y)
+		if y0 >= y1 {
+			return Segment{}, false
+		}
+		s.A.Y, s.B.Y = y0, y1
+		if side == West {
+			s.A.Y, s.B.Y = y1, y0
+		}
+	case North, South:
+		x0, x1 := max(a.Min.X, b.Min.X), min(a.Max.X, b.Max.X)
+		if x0 >= x1 {
+			return Segment{}, false
+		}
+		s.A.X, s.B.X = x0, x1
+		if side == South {
+			s.A.X, s.B.X = x1, x0
+		}
+	}
+	return s, true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,88 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+)
+
+// pixelColor returns the color of the pixel at pt, or Gray if pt lies outside
+// of q.
+func pixelColor(q Quadtree, pt image.Point) Color {
+	if n := Locate(q, pt); n != nil {
+		return n.Color()
+	}
+	return Gray
+}
+
+func testMeasures(t *testing.T, impl Implementation) {
+	files := []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+		"./testdata/labyrinth4.8x8.png",
+	}
+	for _, fn := range files {
+		q := loadTree(t, fn, WithImplementation(impl))
+		bounds := q.Root().Bounds()
+		for _, c := range []Color{Black, White} {
+			// pixel-wise area and perimeter
+			var area, perimeter int
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					pt := image.Pt(x, y)
+					if pixelColor(q, pt) != c {
+						continue
+					}
+					area++
+					for _, d := range []image.Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+						if pixelColor(q, pt.Add(d)) != c {
+							perimeter++
+						}
+					}
+				}
+			}
+
+			if got := Area(q, c); got != area {
+				t.Errorf("%s: Area(%v) = %d, want %d", fn, c, got, area)
+			}
+			if got := Perimeter(q, c); got != perimeter {
+				t.Errorf("%s: Perimeter(%v) = %d, want %d", fn, c, got, perimeter)
+			}
+
+			length := 0
+			for _, s := range Boundary(q, c) {
+				length += s.Len()
+				// the pixel on the right of the segment has color c, the one on
+				// the left doesn't.
+				var right, left image.Point
+				switch s.B.Sub(s.A).Div(s.Len()) {
+				case image.Pt(1, 0):
+					right, left = s.A, s.A.Add(image.Pt(0, -1))
+				case image.Pt(0, 1):
+					right, left = s.A.Add(image.Pt(-1, 0)), s.A
+				case image.Pt(-1, 0):
+					right, left = s.A.Add(image.Pt(-1, -1)), s.A.Add(image.Pt(-1, 0))
+				case image.Pt(0, -1):
+					right, left = s.A.Add(image.Pt(0, -1)), s.A.Add(image.Pt(-1, -1))
+				}
+				if pixelColor(q, right) != c || pixelColor(q, left) == c {
+					t.Fatalf("%s: segment %v of %v boundary is badly oriented", fn, s, c)
+				}
+			}
+			if length != perimeter {
+				t.Errorf("%s: %v boundary length is %d, want %d", fn, c, length, perimeter)
+			}
+		}
+		if got := Area(q, Gray); got != bounds.Dx()*bounds.Dy() {
+			t.Errorf("%s: Area(Gray) = %d, want %d", fn, got, bounds.Dx()*bounds.Dy())
+		}
+	}
+}
+
+func TestBasicTreeMeasures(t *testing.T) {
+	testMeasures(t, Basic)
+}
+
+func TestCNTreeMeasures(t *testing.T) {
+	testMeasures(t, CardinalNeighbour)
+}
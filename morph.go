@@ -0,0 +1,180 @@
+package rquad
+
+import (
+	"errors"
+	"image"
+)
+
+// ErrRadius is returned by morphological operations when the radius of the
+// structuring element is negative.
+var ErrRadius = errors.New("structuring element radius must be greater or equal to 0")
+
+// StructuringElement is the shape used by morphological operations.
+type StructuringElement int
+
+const (
+	// Square is a square structuring element, of side 2*radius+1 pixels.
+	Square StructuringElement = iota
+	// Disc is a disc structuring element, made of the pixels lying at an
+	// euclidean distance lower than, or equal to, radius.
+	Disc
+)
+
+// Dilate returns a new quadtree in which the Black region of q is grown by
+// the structuring element e of the given radius, in pixels. A pixel is Black
+// in the returned quadtree if a Black pixel of q lies in the structuring
+// element centered on it. This is typically used to inflate obstacles by the
+// footprint of a robot.
+//
+// The returned quadtree has the same implementation and creation options as
+// q. It is built from the root down, by deciding the color of each node
+// from the leaves of q lying in its vicinity, without rasterizing the area.
+// Nodes for which no decision can be made when the resolution limit is
+// reached become Black leaves, as when creating a quadtree. Morphological
+// operations return ErrRadius if radius is negative.
+func Dilate(q Quadtree, e StructuringElement, radius int) (Quadtree, error) {
+	return morph(q, e, radius, Black)
+}
+
+// Erode returns a new quadtree in which the Black region of q is shrunk by
+// the structuring element e of the given radius, in pixels. A pixel is Black
+// in the returned quadtree if all the pixels of q lying in the structuring
+// element centered on it are Black, pixels outside of q being considered as
+// Black, so that obstacles touching the border do not shrink from it.
+//
+// Erode is the dual of Dilate, it grows the White region of q. See Dilate
+// for the details common to morphological operations.
+func Erode(q Quadtree, e StructuringElement, radius int) (Quadtree, error) {
+	return morph(q, e, radius, White)
+}
+
+// Open erodes then dilates q, removing the Black regions smaller than the
+// structuring element, and smoothing the others.
+func Open(q Quadtree, e StructuringElement, radius int) (Quadtree, error) {
+	eroded, err := Erode(q, e, radius)
+	if err != nil {
+		return nil, err
+	}
+	return Dilate(eroded, e, radius)
+}
+
+// Close dilates then erodes q, filling the White regions smaller than the
+// structuring element, such as narrow gaps between obstacles.
+func Close(q Quadtree, e StructuringElement, radius int) (Quadtree, error) {
+	dilated, err := Dilate(q, e, radius)
+	if err != nil {
+		return nil, err
+	}
+	return Erode(dilated, e, radius)
+}
+
+// morph returns a new quadtree in which the region of color fg is grown by
+// the structuring element e.
+func morph(q Quadtree, e StructuringElement, radius int, fg Color) (Quadtree, error) {
+	if radius < 0 {
+		return nil, ErrRadius
+	}
+	m := morphology{
+		src:    q.Root(),
+		elem:   e,
+		radius: radius,
+		fg:     fg,
+		opts:   optionsOf(q),
+	}
+	root := m.node(q.Root().Bounds(), nil, Northwest, 0)
+	return newTree(implementationOf(q), root, m.opts), nil
+}
+
+// morphology holds the parameters of a morphological operation.
+type morphology struct {
+	src    Node               // root of the source quadtree
+	elem   StructuringElement // structuring element
+	radius int                // structuring element radius
+	fg     Color              // color of the region to grow
+	opts   options            // options of the created quadtree
+}
+
+// node returns the node of given bounds of the resulting quadtree.
+func (m *morphology) node(bounds image.Rectangle, parent *BasicNode, location Quadrant, depth int) *BasicNode {
+	n := &BasicNode{
+		bounds:   bounds,
+		location: location,
+		color:    Gray,
+	}
+	if parent != nil {
+		n.parent = parent
+	}
+
+	bg := Black
+	if m.fg == Black {
+		bg = White
+	}
+	switch {
+	case !m.touches(m.src, bounds):
+		n.color = bg
+		return n
+	case m.covers(m.src, bounds):
+		n.color = m.fg
+		return n
+	case !m.opts.canSubdivide(bounds, depth):
+		n.color = Black
+		return n
+	}
+
+	for q, qb := range quadrants(bounds) {
+		n.c[q] = m.node(qb, n, Quadrant(q), depth+1)
+	}
+	compactNode(n)
+	return n
+}
+
+// touches reports whether the subtree rooted at n has a leaf of color fg
+// lying within reach of r, that is whether a pixel of r may become of color
+// fg.
+func (m *morphology) touches(n Node, r image.Rectangle) bool {
+	if n.Color() == Gray {
+		if !m.reach(n.Bounds(), r) {
+			return false
+		}
+		for q := Northwest; q <= Southeast; q++ {
+			if m.touches(n.Child(q), r) {
+				return true
+			}
+		}
+		return false
+	}
+	return n.Color() == m.fg && m.reach(n.Bounds(), r)
+}
+
+// covers reports whether the subtree rooted at n has a leaf of color fg that,
+// grown by the structuring element, covers r.
+func (m *morphology) covers(n Node, r image.Rectangle) bool {
+	// the expansion of a node contains the expansion of its descendants.
+	for _, pt := range [4]image.Point{
+		r.Min, {r.Max.X - 1, r.Min.Y}, {r.Min.X, r.Max.Y - 1}, r.Max.Sub(image.Pt(1, 1)),
+	} {
+		if !m.reach(n.Bounds(), image.Rectangle{pt, pt.Add(image.Pt(1, 1))}) {
+			return false
+		}
+	}
+	if n.Color() != Gray {
+		return n.Color() == m.fg
+	}
+	for q := Northwest; q <= Southeast; q++ {
+		if m.covers(n.Child(q), r) {
+			return true
+		}
+	}
+	return false
+}
+
+// reach reports whether a pixel of a lies in the structuring element
+// centered on a pixel of b.
+func (m *morphology) reach(a, b image.Rectangle) bool {
+	gx := max(0, max(a.Min.X-b.Max.X+1, b.Min.X-a.Max.X+1))
+	gy := max(0, max(a.Min.Y-b.Max.Y+1, b.Min.Y-a.Max.Y+1))
+	if m.elem == Disc {
+		return gx*gx+gy*gy <= m.radius*m.radius
+	}
+	return gx <= m.radius && gy <= m.radius
+}
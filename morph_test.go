@@ -0,0 +1,146 @@
+package rquad
+
+import (
+	"fmt"
+	"image"
+	"testing"
+)
+
+// bruteMorph returns the colors of the pixels of q after growing the region
+// of color fg by the structuring element, computed pixel by pixel.
+func bruteMorph(q Quadtree, e StructuringElement, radius int, fg Color) map[image.Point]Color {
+	b := q.Root().Bounds()
+	bg := Black
+	if fg == Black {
+		bg = White
+	}
+	res := make(map[image.Point]Color)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			res[image.Pt(x, y)] = bg
+		search:
+			for dy := -radius; dy <= radius; dy++ {
+				for dx := -radius; dx <= radius; dx++ {
+					if e == Disc && dx*dx+dy*dy > radius*radius {
+						continue
+					}
+					// pixels outside of q are Gray, so they are never
+					// grown, neither when dilating nor when eroding.
+					if pixelColor(q, image.Pt(x+dx, y+dy)) == fg {
+						res[image.Pt(x, y)] = fg
+						break search
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+func checkPixels(t *testing.T, q Quadtree, want map[image.Point]Color) {
+	t.Helper()
+	for pt, c := range want {
+		if got := pixelColor(q, pt); got != c {
+			t.Fatalf("pixel %v is %v, want %v", pt, got, c)
+		}
+	}
+}
+
+func TestDilateErode(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		for _, file := range []string{
+			"./testdata/labyrinth1.32x32.png",
+			"./testdata/labyrinth3.32x32.png",
+		} {
+			q := loadTree(t, file, WithImplementation(impl))
+			for _, e := range []StructuringElement{Square, Disc} {
+				for _, radius := range []int{0, 1, 2, 3} {
+					t.Run(fmt.Sprintf("%v/%s/e=%d/r=%d", impl, file, e, radius), func(t *testing.T) {
+						for _, tt := range []struct {
+							op func(Quadtree, StructuringElement, int) (Quadtree, error)
+							fg Color
+						}{
+							{Dilate, Black},
+							{Erode, White},
+						} {
+							res, err := tt.op(q, e, radius)
+							check(t, err)
+							if implementationOf(res) != impl {
+								t.Fatalf("got implementation %v, want %v", implementationOf(res), impl)
+							}
+							checkPixels(t, res, bruteMorph(q, e, radius, tt.fg))
+							checkCompact(t, res.Root())
+							checkNeighbours(t, res)
+						}
+					})
+				}
+			}
+		}
+	}
+}
+
+func TestOpenClose(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth2.32x32.png")
+	for _, e := range []StructuringElement{Square, Disc} {
+		open, err := Open(q, e, 1)
+		check(t, err)
+		eroded, err := Erode(q, e, 1)
+		check(t, err)
+		checkPixels(t, open, bruteMorph(eroded, e, 1, Black))
+		closed, err := Close(q, e, 1)
+		check(t, err)
+		dilated, err := Dilate(q, e, 1)
+		check(t, err)
+		checkPixels(t, closed, bruteMorph(dilated, e, 1, White))
+
+		// opening is anti-extensive, closing is extensive.
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				pt := image.Pt(x, y)
+				if pixelColor(open, pt) == Black && pixelColor(q, pt) != Black {
+					t.Fatalf("opening adds black pixel %v", pt)
+				}
+				if pixelColor(q, pt) == Black && pixelColor(closed, pt) != Black {
+					t.Fatalf("closing removes black pixel %v", pt)
+				}
+			}
+		}
+	}
+}
+
+func TestDilateResolution(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth3.32x32.png", WithResolution(4))
+	res, err := Dilate(q, Disc, 2)
+	check(t, err)
+	res.ForEachLeaf(Gray, func(n Node) {
+		if n.Bounds().Dx() < 4 || n.Bounds().Dy() < 4 {
+			t.Fatalf("leaf %v is smaller than the resolution", n.Bounds())
+		}
+	})
+	// at the resolution limit, undecided leaves become black
+	for pt, c := range bruteMorph(q, Disc, 2, Black) {
+		if c == Black && pixelColor(res, pt) != Black {
+			t.Fatalf("pixel %v should be black", pt)
+		}
+	}
+}
+
+func TestMorphNegativeRadius(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth1.32x32.png")
+	for _, op := range []func(Quadtree, StructuringElement, int) (Quadtree, error){
+		Dilate, Erode, Open, Close,
+	} {
+		if _, err := op(q, Square, -1); err != ErrRadius {
+			t.Errorf("got error %v, want %v", err, ErrRadius)
+		}
+	}
+}
+
+func TestMorphRootLocation(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth1.32x32.png")
+	res, err := Dilate(q, Square, 1)
+	check(t, err)
+	if got, want := res.Root().Location(), q.Root().Location(); got != want {
+		t.Errorf("root location is %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package rquad
+
+import "image"
+
+// NearestLeaf returns the leaf of color c that is the closest to pt, and the
+// pixel of that leaf that is the closest to pt. If pt lies in a leaf of color
+// c, that leaf and pt itself are returned. Passing Gray as color considers
+// all leaves. If no such leaf exists, NearestLeaf returns a nil Node.
+//
+// NearestLeaf performs a best-first search of the quadtree hierarchy: nodes
+// are explored in order of their distance to pt, so that subtrees lying
+// farther than the nearest leaf are never visited. pt may lie outside of the
+// quadtree.
+func NearestLeaf(q Quadtree, pt image.Point, c Color) (Node, image.Point) {
+	leaves := KNearestLeaves(q, pt, c, 1)
+	if len(leaves) == 0 {
+		return nil, image.Point{}
+	}
+	return leaves[0], closestPixel(leaves[0].Bounds(), pt)
+}
+
+// KNearestLeaves returns the k leaves of color c that are the closest to pt,
+// ordered by increasing distance. Passing Gray as color considers all leaves.
+// Less than k leaves are returned if the quadtree doesn't have enough leaves
+// of color c.
+//
+// The distance between pt and a leaf is the distance between pt and the
+// closest pixel of the leaf.
+func KNearestLeaves(q Quadtree, pt image.Point, c Color, k int) []Node {
+	var (
+		pq     priorityQueue
+		leaves []Node
+	)
+	root := q.Root()
+	pq.push(queueItem{node: root, dist: rectDist2(root.Bounds(), pt)})
+	for len(pq) > 0 && len(leaves) < k {
+		n := pq.pop().node
+		switch {
+		case n.Color() == Gray:
+			for q := Northwest; q <= Southeast; q++ {
+				child := n.Child(q)
+				pq.push(queueItem{node: child, dist: rectDist2(child.Bounds(), pt)})
+			}
+		case c == Gray || n.Color() == c:
+			leaves = append(leaves, n)
+		}
+	}
+	return leaves
+}
+
+// closestPixel returns the pixel of r that is the closest to pt.
+func closestPixel(r image.Rectangle, pt image.Point) image.Point {
+	return image.Pt(
+		max(r.Min.X, min(pt.X, r.Max.X-1)),
+		max(r.Min.Y, min(pt.Y, r.Max.Y-1)),
+	)
+}
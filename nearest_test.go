@@ -0,0 +1,87 @@
+package rquad
+
+import (
+	"image"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func testNearestLeaf(t *testing.T, impl Implementation) {
+	var trees []Quadtree
+	for _, fn := range []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+	} {
+		trees = append(trees, loadTree(t, fn, WithImplementation(impl)))
+	}
+	r := rand.New(rand.NewSource(99))
+
+	for _, q := range trees {
+		for _, c := range []Color{Black, White, Gray} {
+			testKNearestLeaves(t, q, c, r)
+		}
+	}
+}
+
+func testKNearestLeaves(t *testing.T, q Quadtree, c Color, r *rand.Rand) {
+	var leaves []Node
+	q.ForEachLeaf(c, func(n Node) { leaves = append(leaves, n) })
+
+	for i := 0; i < 20; i++ {
+		pt := image.Pt(r.Intn(48)-8, r.Intn(48)-8)
+		k := r.Intn(10) + 1
+
+		// brute force
+		sort.Slice(leaves, func(i, j int) bool {
+			return rectDist2(leaves[i].Bounds(), pt) < rectDist2(leaves[j].Bounds(), pt)
+		})
+
+		got := KNearestLeaves(q, pt, c, k)
+		if len(got) != min(k, len(leaves)) {
+			t.Fatalf("KNearestLeaves(%v, %v, %d) returned %d leaves", pt, c, k, len(got))
+		}
+		for j, n := range got {
+			if n.Color() != c && c != Gray {
+				t.Fatalf("KNearestLeaves(%v, %v, %d)[%d] has color %v", pt, c, k, j, n.Color())
+			}
+			if rectDist2(n.Bounds(), pt) != rectDist2(leaves[j].Bounds(), pt) {
+				t.Fatalf("KNearestLeaves(%v, %v, %d)[%d] = %v, want a leaf at distance² %v",
+					pt, c, k, j, n.Bounds(), rectDist2(leaves[j].Bounds(), pt))
+			}
+		}
+
+		n, closest := NearestLeaf(q, pt, c)
+		if n != got[0] {
+			t.Fatalf("NearestLeaf(%v, %v) = %v, want %v", pt, c, n.Bounds(), got[0].Bounds())
+		}
+		d := closest.Sub(pt)
+		if !closest.In(n.Bounds()) || float64(d.X*d.X+d.Y*d.Y) != rectDist2(n.Bounds(), pt) {
+			t.Fatalf("NearestLeaf(%v, %v) returned closest point %v for leaf %v", pt, c, closest, n.Bounds())
+		}
+	}
+}
+
+func TestBasicTreeNearestLeaf(t *testing.T) {
+	testNearestLeaf(t, Basic)
+}
+
+func TestCNTreeNearestLeaf(t *testing.T) {
+	testNearestLeaf(t, CardinalNeighbour)
+}
+
+func TestNearestLeafInside(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth1.32x32.png")
+	pt := image.Pt(3, 3)
+	n, closest := NearestLeaf(q, pt, Locate(q, pt).Color())
+	if n != Locate(q, pt) || closest != pt {
+		t.Errorf("NearestLeaf should return the leaf containing the point")
+	}
+
+	white, err := New(NewShapeScanner(image.Rect(0, 0, 8, 8)))
+	check(t, err)
+	if n, _ := NearestLeaf(white, pt, Black); n != nil {
+		t.Errorf("NearestLeaf should return nil if no leaf has the given color, got %v", n.Bounds())
+	}
+}
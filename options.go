@@ -0,0 +1,170 @@
+package rquad
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/arl/imgtools/imgscan"
+)
+
+// Errors returned by quadtree constructors. They can be inspected with
+// errors.Is.
+var (
+	// ErrResolution is returned when the requested resolution, or minimum
+	// leaf size, is not greater than 0.
+	ErrResolution = errors.New("resolution must be greater than 0")
+
+	// ErrTooSmall is returned when the scanned area is too small for the root
+	// node to be subdivided at least once with the requested resolution, or
+	// minimum leaf size.
+	ErrTooSmall = errors.New("the image smaller dimension must be greater or equal to twice the resolution")
+
+	// ErrNotPowerOf2 is returned when a quadtree implementation requiring a
+	// square and power-of-2 sized image is given another image.
+	ErrNotPowerOf2 = errors.New("image must be a square with power-of-2 dimensions")
+
+	// ErrMaxDepth is returned when the requested maximum depth is not greater
+	// than 0.
+	ErrMaxDepth = errors.New("maximum depth must be greater than 0")
+
+	// ErrCapacity is returned when the requested bucket capacity of a
+	// PointTree is not greater than 0.
+	ErrCapacity = errors.New("capacity must be greater than 0")
+
+	// ErrEmptyBounds is returned when the area covered by a PointTree is
+	// empty.
+	ErrEmptyBounds = errors.New("bounds must not be empty")
+)
+
+// Implementation identifies a Quadtree implementation provided by this
+// package.
+type Implementation int
+
+// Possible values for the Implementation type.
+const (
+	// Basic selects the BasicTree implementation.
+	Basic Implementation = iota
+
+	// CardinalNeighbour selects the CNTree implementation.
+	CardinalNeighbour
+)
+
+// An Option configures the creation of a quadtree.
+type Option func(*options)
+
+// options holds the creation parameters shared by all quadtree
+// implementations.
+type options struct {
+	impl      Implementation // quadtree implementation
+	minWidth  int            // minimum width of a leaf node
+	minHeight int            // minimum height of a leaf node
+	maxDepth  int            // maximum depth of a leaf node
+}
+
+// newOptions returns the default options, modified by opts.
+func newOptions(opts ...Option) options {
+	o := options{
+		impl:      Basic,
+		minWidth:  1,
+		minHeight: 1,
+		maxDepth:  math.MaxInt32,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithImplementation sets the Quadtree implementation that New creates. By
+// default, New creates a BasicTree.
+func WithImplementation(impl Implementation) Option {
+	return func(o *options) {
+		o.impl = impl
+	}
+}
+
+// WithResolution sets the resolution of the quadtree, that is the smallest
+// size in pixels that can have a leaf node. No further subdivisions will be
+// performed on a node if its width or height is equal to this value. The
+// default resolution is 1.
+//
+// WithResolution is equivalent to WithMinLeafSize(resolution, resolution).
+func WithResolution(resolution int) Option {
+	return WithMinLeafSize(resolution, resolution)
+}
+
+// WithMinLeafSize sets independent resolutions on the horizontal and vertical
+// axes, this is useful when a pixel doesn't represent a square area. No
+// further subdivisions will be performed on a node if half its width is
+// smaller than width, or if half its height is smaller than height.
+//
+// Nodes of a CNTree are squares, so they stop being subdivided as soon as one
+// of the conditions holds.
+func WithMinLeafSize(width, height int) Option {
+	return func(o *options) {
+		o.minWidth = width
+		o.minHeight = height
+	}
+}
+
+// WithMaxDepth limits the depth of the quadtree, the root node being at depth
+// 0. Nodes lying at depth maxDepth won't be subdivided, whatever their size.
+// By default, the depth is only limited by the resolution.
+func WithMaxDepth(maxDepth int) Option {
+	return func(o *options) {
+		o.maxDepth = maxDepth
+	}
+}
+
+// validate checks that a quadtree can be created over the given bounds.
+func (o *options) validate(bounds image.Rectangle) error {
+	if o.minWidth < 1 || o.minHeight < 1 {
+		return ErrResolution
+	}
+	if o.maxDepth < 1 {
+		return ErrMaxDepth
+	}
+
+	// To ensure a consistent behavior and eliminate corner cases,
+	// the Quadtree's root node needs to have children. Thus, the
+	// first instantiated Node needs to always be subdivided.
+	// This condition asserts the resolution is respected.
+	if !o.canSubdivide(bounds, 0) {
+		return ErrTooSmall
+	}
+	return nil
+}
+
+// canSubdivide reports whether a node with the given bounds, lying at the
+// given depth, can be subdivided.
+func (o *options) canSubdivide(bounds image.Rectangle, depth int) bool {
+	return depth < o.maxDepth &&
+		bounds.Dx()/2 >= o.minWidth &&
+		bounds.Dy()/2 >= o.minHeight
+}
+
+// New creates a region quadtree from a scannable rectangular area.
+//
+// By default New creates a BasicTree with a resolution of 1, opts allow to
+// change that. The returned error, if any, can be compared with errors.Is to
+// ErrResolution, ErrMaxDepth, ErrTooSmall or ErrNotPowerOf2.
+func New(scanner imgscan.Scanner, opts ...Option) (Quadtree, error) {
+	o := newOptions(opts...)
+	switch o.impl {
+	case Basic:
+		q, err := createBasicTree(scanner, o)
+		if err != nil {
+			return nil, err
+		}
+		return q, nil
+	case CardinalNeighbour:
+		q, err := createCNTree(scanner, o)
+		if err != nil {
+			return nil, err
+		}
+		return q, nil
+	}
+	return nil, fmt.Errorf("unknown quadtree implementation: %d", o.impl)
+}
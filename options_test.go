@@ -0,0 +1,136 @@
+package rquad
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/arl/go-rquad/internal"
+	"github.com/arl/imgtools/binimg"
+	"github.com/arl/imgtools/imgscan"
+)
+
+func TestNewErrors(t *testing.T) {
+	var testTbl = []struct {
+		w, h int            // bitmap dimensions
+		impl Implementation // quadtree implementation
+		res  int            // resolution
+		err  error          // expected error
+	}{
+		{10, 10, Basic, 0, ErrResolution},
+		{10, 10, Basic, 1, nil},
+		{10, 10, Basic, 6, ErrTooSmall},
+		{10, 13, Basic, 6, ErrTooSmall},
+		{13, 10, Basic, 6, ErrTooSmall},
+		{12, 12, Basic, 6, nil},
+		{16, 16, CardinalNeighbour, 0, ErrResolution},
+		{16, 16, CardinalNeighbour, 1, nil},
+		{16, 16, CardinalNeighbour, 8, nil},
+		{16, 16, CardinalNeighbour, 9, ErrTooSmall},
+		{16, 8, CardinalNeighbour, 1, ErrNotPowerOf2},
+		{12, 12, CardinalNeighbour, 1, ErrNotPowerOf2},
+	}
+
+	for _, tt := range testTbl {
+		bm := binimg.New(image.Rect(0, 0, tt.w, tt.h))
+		scanner, err := imgscan.NewScanner(bm)
+		check(t, err)
+		q, err := New(scanner, WithImplementation(tt.impl), WithResolution(tt.res))
+		if !errors.Is(err, tt.err) {
+			t.Errorf("(%d,%d,%v,%d): want err %v, got %v",
+				tt.w, tt.h, tt.impl, tt.res, tt.err, err)
+		}
+		if err != nil && q != nil {
+			t.Errorf("(%d,%d,%v,%d): want nil quadtree on error, got %v",
+				tt.w, tt.h, tt.impl, tt.res, q)
+		}
+	}
+}
+
+func TestNewImplementation(t *testing.T) {
+	bm := binimg.New(image.Rect(0, 0, 16, 16))
+	scanner, err := imgscan.NewScanner(bm)
+	check(t, err)
+
+	q, err := New(scanner)
+	check(t, err)
+	if _, ok := q.(*BasicTree); !ok {
+		t.Errorf("want *BasicTree by default, got %T", q)
+	}
+
+	q, err = New(scanner, WithImplementation(CardinalNeighbour))
+	check(t, err)
+	if _, ok := q.(*CNTree); !ok {
+		t.Errorf("want *CNTree, got %T", q)
+	}
+}
+
+func TestNewMaxDepthAndMinLeafSize(t *testing.T) {
+	var testTbl = []struct {
+		impl           Implementation // quadtree implementation
+		opts           []Option       // creation options
+		maxDepth       int            // max expected leaf depth
+		minW, minH     int            // min expected leaf size
+		wantErr        error          // expected error
+		wantDeepest    int            // expected depth of the deepest leaf
+		checkLocateAll bool           // compare Locate with pointLocation
+	}{
+		{Basic, []Option{WithMaxDepth(2)}, 2, 1, 1, nil, 2, true},
+		{Basic, []Option{WithMaxDepth(3), WithResolution(2)}, 3, 2, 2, nil, 3, true},
+		{Basic, []Option{WithMinLeafSize(1, 4)}, 5, 1, 4, nil, 3, true},
+		{Basic, []Option{WithMinLeafSize(8, 2)}, 5, 8, 2, nil, 2, true},
+		{Basic, []Option{WithMaxDepth(0)}, 0, 0, 0, ErrMaxDepth, 0, false},
+		{Basic, []Option{WithMinLeafSize(0, 1)}, 0, 0, 0, ErrResolution, 0, false},
+		{Basic, []Option{WithMinLeafSize(1, 17)}, 0, 0, 0, ErrTooSmall, 0, false},
+		{CardinalNeighbour, []Option{WithMaxDepth(1)}, 1, 1, 1, nil, 1, true},
+		{CardinalNeighbour, []Option{WithMaxDepth(2)}, 2, 1, 1, nil, 2, true},
+		{CardinalNeighbour, []Option{WithMaxDepth(4), WithResolution(4)}, 3, 4, 4, nil, 3, true},
+		{CardinalNeighbour, []Option{WithMinLeafSize(1, 4)}, 3, 4, 4, nil, 3, true},
+		{CardinalNeighbour, []Option{WithMinLeafSize(2, 1), WithMaxDepth(3)}, 3, 2, 2, nil, 3, true},
+	}
+
+	bm, err := internal.LoadPNG("./testdata/labyrinth2.32x32.png")
+	check(t, err)
+	scanner, err := imgscan.NewScanner(bm)
+	check(t, err)
+
+	for i, tt := range testTbl {
+		opts := append([]Option{WithImplementation(tt.impl)}, tt.opts...)
+		q, err := New(scanner, opts...)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("test %d: want err %v, got %v", i, tt.wantErr, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		deepest := 0
+		q.ForEachLeaf(Gray, func(n Node) {
+			d := Depth(n)
+			if d > tt.maxDepth {
+				t.Errorf("test %d: leaf %v has depth %d > %d", i, n.Bounds(), d, tt.maxDepth)
+			}
+			if d > deepest {
+				deepest = d
+			}
+			if n.Bounds().Dx() < tt.minW || n.Bounds().Dy() < tt.minH {
+				t.Errorf("test %d: leaf %v is smaller than %dx%d", i, n.Bounds(), tt.minW, tt.minH)
+			}
+		})
+		if deepest != tt.wantDeepest {
+			t.Errorf("test %d: want deepest leaf at depth %d, got %d", i, tt.wantDeepest, deepest)
+		}
+
+		if tt.checkLocateAll {
+			b := q.Root().Bounds()
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					pt := image.Pt(x, y)
+					if got, want := Locate(q, pt), pointLocation(q.Root(), pt); got != want {
+						t.Fatalf("test %d: Locate(%v) = %v, want %v", i, pt, got.Bounds(), want.Bounds())
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package rquad
+
+import "errors"
+
+// ErrNotAdjacent is returned when a path contains consecutive leaves that
+// are not neighbours.
+var ErrNotAdjacent = errors.New("consecutive path leaves must be adjacent")
+
+// Portals returns the portals of a path, that is the edges shared by each
+// pair of consecutive leaves of path. The returned slice has len(path)-1
+// elements, or none if path has less than 2 leaves. Portals returns
+// ErrNotAdjacent if 2 consecutive leaves don't share an edge.
+//
+// A portal is oriented from left to right, considering an agent going from a
+// leaf to the next one, the y axis pointing downwards, as it does in images.
+func Portals(path []Node) ([]Segment, error) {
+	var portals []Segment
+	for i := 1; i < len(path); i++ {
+		s, ok := sharedEdge(path[i-1].Bounds(), path[i].Bounds())
+		if !ok {
+			return nil, ErrNotAdjacent
+		}
+		portals = append(portals, s)
+	}
+	return portals, nil
+}
+
+// StringPull returns the shortest polyline going from start to goal through
+// the given portals, as returned by Portals, following the funnel algorithm.
+//
+// The returned polyline starts with start and ends with goal, other points
+// are portal endpoints, that is corners of the path leaves, where the
+// polyline turns.
+func StringPull(start, goal FPoint, portals []Segment) []FPoint {
+	// the funnel is made of the apex, and of the left and right portal
+	// endpoints lying the closest to the middle of the funnel.
+	lefts := make([]FPoint, 0, len(portals)+2)
+	rights := make([]FPoint, 0, len(portals)+2)
+	lefts, rights = append(lefts, start), append(rights, start)
+	for _, p := range portals {
+		lefts, rights = append(lefts, fpoint(p.A)), append(rights, fpoint(p.B))
+	}
+	lefts, rights = append(lefts, goal), append(rights, goal)
+
+	pts := []FPoint{start}
+	apex, left, right := start, start, start
+	apexIdx, leftIdx, rightIdx := 0, 0, 0
+	for i := 1; i < len(lefts); i++ {
+		l, r := lefts[i], rights[i]
+
+		// try to narrow the funnel on the right side
+		if turn(apex, right, r) <= 0 {
+			if apex == right || turn(apex, left, r) > 0 {
+				right, rightIdx = r, i
+			} else {
+				// right crosses over left: left becomes the new apex
+				pts = append(pts, left)
+				apex, apexIdx = left, leftIdx
+				right, rightIdx = apex, apexIdx
+				i = apexIdx
+				continue
+			}
+		}
+
+		// try to narrow the funnel on the left side
+		if turn(apex, left, l) >= 0 {
+			if apex == left || turn(apex, right, l) < 0 {
+				left, leftIdx = l, i
+			} else {
+				// left crosses over right: right becomes the new apex
+				pts = append(pts, right)
+				apex, apexIdx = right, rightIdx
+				left, leftIdx = apex, apexIdx
+				i = apexIdx
+				continue
+			}
+		}
+	}
+	if pts[len(pts)-1] != goal {
+		pts = append(pts, goal)
+	}
+	return pts
+}
+
+// turn returns a positive value if c lies on the right of the line going
+// from a to b, the y axis pointing downwards, a negative value if c lies on
+// its left, and 0 if a, b and c are collinear.
+func turn(a, b, c FPoint) float64 {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+// PruneWaypoints removes the unnecessary waypoints of the polyline pts: from
+// each waypoint, the polyline goes directly to the farthest following
+// waypoint in line of sight, that is such that the segment joining them
+// doesn't cross any Black leaf of q.
+//
+// A segment running along the border of a Black leaf is considered as
+// crossing it, whatever the side of the segment the leaf lies on, as is a
+// segment passing through the corner shared by 2 diagonally adjacent Black
+// leaves, so that the returned polyline never goes through Black leaves,
+// even between 2 of them. Waypoints may lie on the border of Black leaves.
+func PruneWaypoints(q Quadtree, pts []FPoint) []FPoint {
+	if len(pts) < 3 {
+		return pts
+	}
+	res := []FPoint{pts[0]}
+	for i := 0; i < len(pts)-1; {
+		next := i + 1
+		for j := len(pts) - 1; j > next; j-- {
+			if segmentClear(q, pts[i], pts[j]) {
+				next = j
+				break
+			}
+		}
+		res = append(res, pts[next])
+		i = next
+	}
+	return res
+}
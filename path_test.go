@@ -0,0 +1,268 @@
+package rquad
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// leafPath returns a path of adjacent leaves of the same color, going from
+// the leaf containing a to the one containing b, found with a breadth-first
+// search, or nil.
+func leafPath(q Quadtree, a, b image.Point) []Node {
+	start, goal := Locate(q, a), Locate(q, b)
+	prev := map[Node]Node{start: nil}
+	queue := []Node{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n == goal {
+			var path []Node
+			for ; n != nil; n = prev[n] {
+				path = append([]Node{n}, path...)
+			}
+			return path
+		}
+		ForEachNeighbour(n, func(nb Node) {
+			if _, ok := prev[nb]; !ok && nb.Color() == start.Color() {
+				prev[nb] = n
+				queue = append(queue, nb)
+			}
+		})
+	}
+	return nil
+}
+
+func polylineLen(pts []FPoint) float64 {
+	l := 0.0
+	for i := 1; i < len(pts); i++ {
+		l += math.Hypot(pts[i].X-pts[i-1].X, pts[i].Y-pts[i-1].Y)
+	}
+	return l
+}
+
+// inCorridor reports whether pt lies in one of the leaves of path.
+func inCorridor(path []Node, pt FPoint) bool {
+	for _, n := range path {
+		b := n.Bounds()
+		if pt.X >= float64(b.Min.X)-epsilon && pt.X <= float64(b.Max.X)+epsilon &&
+			pt.Y >= float64(b.Min.Y)-epsilon && pt.Y <= float64(b.Max.Y)+epsilon {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPortals(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth1.32x32.png")
+	a := Locate(q, image.Pt(0, 0))
+	var b Node
+	ForEachNeighbour(a, func(n Node) {
+		if n.Bounds().Min.X == a.Bounds().Max.X {
+			b = n
+		}
+	})
+	portals, err := Portals([]Node{a, b})
+	check(t, err)
+	// going east, left is north
+	x := a.Bounds().Max.X
+	if len(portals) != 1 || portals[0].A.X != x || portals[0].B.X != x || portals[0].A.Y >= portals[0].B.Y {
+		t.Errorf("got portals %v, want a vertical portal at x=%d, going south", portals, x)
+	}
+
+	if _, err := Portals([]Node{a, Locate(q, image.Pt(31, 31))}); err != ErrNotAdjacent {
+		t.Errorf("want ErrNotAdjacent, got %v", err)
+	}
+	if portals, err := Portals([]Node{a}); err != nil || len(portals) != 0 {
+		t.Errorf("got %v, %v, want no portals", portals, err)
+	}
+}
+
+func TestStringPull(t *testing.T) {
+	r := rand.New(rand.NewSource(99))
+	for _, file := range []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+	} {
+		q := loadTree(t, file)
+		var tested int
+		for tested < 30 {
+			a, b := image.Pt(r.Intn(32), r.Intn(32)), image.Pt(r.Intn(32), r.Intn(32))
+			if Locate(q, a).Color() != White || Locate(q, b).Color() != White {
+				continue
+			}
+			path := leafPath(q, a, b)
+			if path == nil {
+				continue
+			}
+			tested++
+
+			portals, err := Portals(path)
+			check(t, err)
+			start, goal := pixelCenter(a), pixelCenter(b)
+			pts := StringPull(start, goal, portals)
+			if pts[0] != start || pts[len(pts)-1] != goal {
+				t.Fatalf("%s: polyline %v doesn't go from %v to %v", file, pts, start, goal)
+			}
+
+			// the polyline must stay in the corridor formed by the path
+			// leaves, and be shorter than the one joining portal middles.
+			for i := 1; i < len(pts); i++ {
+				for s := 0.0; s <= 1; s += 1.0 / 64 {
+					pt := FPoint{pts[i-1].X + s*(pts[i].X-pts[i-1].X), pts[i-1].Y + s*(pts[i].Y-pts[i-1].Y)}
+					if !inCorridor(path, pt) {
+						t.Fatalf("%s: polyline %v leaves the corridor at %v", file, pts, pt)
+					}
+				}
+			}
+			mids := []FPoint{start}
+			for _, p := range portals {
+				mids = append(mids, FPoint{float64(p.A.X+p.B.X) / 2, float64(p.A.Y+p.B.Y) / 2})
+			}
+			mids = append(mids, goal)
+			if polylineLen(pts) > polylineLen(mids)+epsilon {
+				t.Fatalf("%s: polyline %v is longer than %v", file, pts, mids)
+			}
+		}
+	}
+}
+
+func TestStringPullCorner(t *testing.T) {
+	// an L-shaped corridor, going east, then south, around a black square.
+	q, err := New(NewShapeScanner(image.Rect(0, 0, 16, 16),
+		NewPolygon(FPoint{0, 8}, FPoint{8, 8}, FPoint{8, 16}, FPoint{0, 16})))
+	check(t, err)
+
+	a, b := image.Pt(1, 4), image.Pt(12, 15)
+	path := leafPath(q, a, b)
+	portals, err := Portals(path)
+	check(t, err)
+	pts := StringPull(pixelCenter(a), pixelCenter(b), portals)
+	want := []FPoint{pixelCenter(a), {8, 8}, pixelCenter(b)}
+	if len(pts) != len(want) {
+		t.Fatalf("got polyline %v, want %v", pts, want)
+	}
+	for i := range pts {
+		if pts[i] != want[i] {
+			t.Fatalf("got polyline %v, want %v", pts, want)
+		}
+	}
+}
+
+func TestPruneWaypoints(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth2.32x32.png")
+	r := rand.New(rand.NewSource(99))
+	var tested, shortened int
+	for tested < 30 {
+		a, b := image.Pt(r.Intn(32), r.Intn(32)), image.Pt(r.Intn(32), r.Intn(32))
+		if Locate(q, a).Color() != White || Locate(q, b).Color() != White {
+			continue
+		}
+		path := leafPath(q, a, b)
+		if len(path) < 2 {
+			continue
+		}
+		tested++
+
+		// jagged polyline joining leaf centers
+		pts := []FPoint{pixelCenter(a)}
+		for _, n := range path[1 : len(path)-1] {
+			pts = append(pts, toFRect(n.Bounds()).center())
+		}
+		pts = append(pts, pixelCenter(b))
+
+		pruned := PruneWaypoints(q, pts)
+		if pruned[0] != pts[0] || pruned[len(pruned)-1] != pts[len(pts)-1] {
+			t.Fatalf("pruned polyline %v doesn't keep endpoints of %v", pruned, pts)
+		}
+		if len(pruned) > len(pts) || polylineLen(pruned) > polylineLen(pts)+epsilon {
+			t.Fatalf("pruned polyline %v is longer than %v", pruned, pts)
+		}
+		for i := 1; i < len(pruned); i++ {
+			if !segmentClear(q, pruned[i-1], pruned[i]) {
+				t.Fatalf("pruned polyline %v crosses a black leaf", pruned)
+			}
+		}
+		if len(pruned) < len(pts) {
+			shortened++
+		}
+	}
+	if shortened == 0 {
+		t.Errorf("no polyline has been shortened, test is useless")
+	}
+}
+
+func TestPruneWaypointsAlongBorder(t *testing.T) {
+	testTbl := []struct {
+		side  Side
+		black *Polygon // obstacle touching the line x=8 or y=8
+		pts   []FPoint // polyline running along that line
+	}{
+		{North, NewPolygon(FPoint{0, 4}, FPoint{16, 4}, FPoint{16, 8}, FPoint{0, 8}),
+			[]FPoint{{2, 8}, {8, 8}, {14, 8}}},
+		{South, NewPolygon(FPoint{0, 8}, FPoint{16, 8}, FPoint{16, 12}, FPoint{0, 12}),
+			[]FPoint{{2, 8}, {8, 8}, {14, 8}}},
+		{West, NewPolygon(FPoint{4, 0}, FPoint{8, 0}, FPoint{8, 16}, FPoint{4, 16}),
+			[]FPoint{{8, 2}, {8, 8}, {8, 14}}},
+		{East, NewPolygon(FPoint{8, 0}, FPoint{12, 0}, FPoint{12, 16}, FPoint{8, 16}),
+			[]FPoint{{8, 2}, {8, 8}, {8, 14}}},
+	}
+	for _, tt := range testTbl {
+		q, err := New(NewShapeScanner(image.Rect(0, 0, 16, 16), tt.black))
+		check(t, err)
+		if pruned := PruneWaypoints(q, tt.pts); len(pruned) != len(tt.pts) {
+			t.Errorf("obstacle on the %v side: got %v, the segments along its border should be blocked", tt.side, pruned)
+		}
+		// the same polyline, a pixel away from the obstacle
+		far := make([]FPoint, len(tt.pts))
+		for i, pt := range tt.pts {
+			far[i] = pt
+			switch tt.side {
+			case North:
+				far[i].Y++
+			case South:
+				far[i].Y--
+			case West:
+				far[i].X++
+			case East:
+				far[i].X--
+			}
+		}
+		if pruned := PruneWaypoints(q, far); len(pruned) != 2 {
+			t.Errorf("obstacle on the %v side: got %v, want the middle waypoint removed", tt.side, pruned)
+		}
+	}
+}
+
+func TestPruneWaypointsCorners(t *testing.T) {
+	// 2 black squares touching by their corner at (8,8)
+	q, err := New(NewShapeScanner(image.Rect(0, 0, 16, 16),
+		NewPolygon(FPoint{8, 4}, FPoint{12, 4}, FPoint{12, 8}, FPoint{8, 8}),
+		NewPolygon(FPoint{4, 8}, FPoint{8, 8}, FPoint{8, 12}, FPoint{4, 12}),
+	))
+	check(t, err)
+	pts := []FPoint{{6.5, 6.5}, {6.5, 2.5}, {13.5, 2.5}, {13.5, 9.5}, {9.5, 9.5}}
+	pruned := PruneWaypoints(q, pts)
+	if len(pruned) == 2 {
+		t.Errorf("pruned polyline %v goes between the black squares", pruned)
+	}
+	for i := 1; i < len(pruned); i++ {
+		if !segmentClear(q, pruned[i-1], pruned[i]) {
+			t.Fatalf("pruned polyline %v crosses a black leaf", pruned)
+		}
+	}
+
+	// waypoints lying on the corner of a black leaf, as returned by
+	// StringPull, don't prevent shortcuts, in both directions.
+	q, err = New(NewShapeScanner(image.Rect(0, 0, 8, 8), NewPolygon(FPoint{0, 0}, FPoint{4, 0}, FPoint{4, 4}, FPoint{0, 4})))
+	check(t, err)
+	pts = []FPoint{{6, 7}, {6, 6}, {4, 4}}
+	for i := 0; i < 2; i++ {
+		if pruned := PruneWaypoints(q, pts); len(pruned) != 2 {
+			t.Errorf("PruneWaypoints(%v) = %v, want the middle waypoint removed", pts, pruned)
+		}
+		pts[0], pts[2] = pts[2], pts[0]
+	}
+}
@@ -0,0 +1,245 @@
+package rquad
+
+import (
+	"image"
+)
+
+// PointNode is a node of a PointTree.
+//
+// Leaf nodes store the points lying in their bounds, a leaf is Black if it
+// contains at least one point, White if it's empty.
+type PointNode struct {
+	BasicNode
+	points []image.Point // points stored in the leaf
+}
+
+// Points returns the points stored in the node. Only leaf nodes store points.
+func (n *PointNode) Points() []image.Point {
+	return n.points
+}
+
+// PointTree is a point-region quadtree (PR quadtree).
+//
+// Contrary to region quadtrees, the decomposition of a PointTree doesn't
+// depend on an image but on a set of points. A leaf node is subdivided as soon
+// as it contains more points than the bucket capacity, and 4 sibling leaves
+// are merged back when they collectively contain no more points than the
+// capacity.
+//
+// PointTree implements the Quadtree interface, so Locate and
+// ForEachNeighbour can be used on it.
+type PointTree struct {
+	capacity int        // bucket capacity of a leaf
+	root     *PointNode // root node
+	len      int        // number of points
+}
+
+// NewPointTree creates an empty point-region quadtree covering bounds.
+//
+// capacity is the maximum number of points a leaf can hold before it gets
+// subdivided. A leaf that can't be subdivided, because its width or height is
+// 1, holds an unlimited number of points.
+func NewPointTree(bounds image.Rectangle, capacity int) (*PointTree, error) {
+	if capacity < 1 {
+		return nil, ErrCapacity
+	}
+	if bounds.Empty() {
+		return nil, ErrEmptyBounds
+	}
+	return &PointTree{
+		capacity: capacity,
+		root: &PointNode{
+			BasicNode: BasicNode{
+				color:  White,
+				bounds: bounds,
+			},
+		},
+	}, nil
+}
+
+// Root returns the quadtree root node.
+func (q *PointTree) Root() Node {
+	return q.root
+}
+
+// ForEachLeaf calls the given function for each leaf node of the quadtree.
+//
+// Successive calls to the provided function are performed in no particular
+// order. The color parameter allows to loop on the leaves of a particular
+// color, Black (non-empty) or White (empty).
+// NOTE: As by definition, Gray leaves do not exist, passing Gray to
+// ForEachLeaf should return all leaves, independently of their color.
+func (q *PointTree) ForEachLeaf(color Color, fn func(Node)) {
+	var visit func(n *PointNode)
+	visit = func(n *PointNode) {
+		if n.color != Gray {
+			if color == Gray || n.color == color {
+				fn(n)
+			}
+			return
+		}
+		for _, c := range n.c {
+			visit(c.(*PointNode))
+		}
+	}
+	visit(q.root)
+}
+
+// Len returns the number of points stored in the quadtree.
+func (q *PointTree) Len() int {
+	return q.len
+}
+
+// Insert adds pt to the quadtree. Insert returns false if pt lies outside of
+// the quadtree bounds.
+func (q *PointTree) Insert(pt image.Point) bool {
+	if !pt.In(q.root.bounds) {
+		return false
+	}
+	n := q.leaf(pt)
+	n.points = append(n.points, pt)
+	n.color = Black
+	if len(n.points) > q.capacity {
+		q.subdivide(n)
+	}
+	q.len++
+	return true
+}
+
+// Delete removes one occurrence of pt from the quadtree. Delete returns false
+// if pt was not found.
+func (q *PointTree) Delete(pt image.Point) bool {
+	if !pt.In(q.root.bounds) {
+		return false
+	}
+	n := q.leaf(pt)
+	found := false
+	for i := range n.points {
+		if n.points[i] == pt {
+			last := len(n.points) - 1
+			n.points[i] = n.points[last]
+			n.points = n.points[:last]
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	if len(n.points) == 0 {
+		n.color = White
+	}
+	q.len--
+
+	// merge ancestors which children do not hold enough points anymore
+	p := n.Parent()
+	for p != nil && q.merge(p.(*PointNode)) {
+		p = p.Parent()
+	}
+	return true
+}
+
+// KNearest returns the k points of the quadtree that are the closest to pt,
+// ordered by increasing distance.
+//
+// It performs a best-first search of the quadtree: nodes are explored in order
+// of their distance to pt, so that subtrees lying farther than the k-th
+// nearest point are never visited.
+func (q *PointTree) KNearest(pt image.Point, k int) []image.Point {
+	var (
+		pq  priorityQueue
+		res []image.Point
+	)
+	pq.push(queueItem{node: q.root, dist: rectDist2(q.root.bounds, pt)})
+	for len(pq) > 0 && len(res) < k {
+		item := pq.pop()
+		if item.node == nil {
+			res = append(res, item.pt)
+			continue
+		}
+		n := item.node.(*PointNode)
+		if n.color == Gray {
+			for _, c := range n.c {
+				pq.push(queueItem{node: c, dist: rectDist2(c.Bounds(), pt)})
+			}
+			continue
+		}
+		for _, p := range n.points {
+			d := p.Sub(pt)
+			pq.push(queueItem{pt: p, dist: float64(d.X*d.X + d.Y*d.Y)})
+		}
+	}
+	return res
+}
+
+// leaf returns the leaf node containing pt.
+func (q *PointTree) leaf(pt image.Point) *PointNode {
+	return pointLocation(q.root, pt).(*PointNode)
+}
+
+// subdivide subdivides the leaf n and distributes its points among its
+// children, which are recursively subdivided if needed.
+func (q *PointTree) subdivide(n *PointNode) {
+	if n.bounds.Dx() < 2 || n.bounds.Dy() < 2 {
+		// n can't be subdivided
+		return
+	}
+
+	bounds := quadrants(n.bounds)
+	var children [4]*PointNode
+	for i := range children {
+		children[i] = &PointNode{
+			BasicNode: BasicNode{
+				parent:   n,
+				color:    White,
+				bounds:   bounds[i],
+				location: Quadrant(i),
+			},
+		}
+		n.c[i] = children[i]
+	}
+	for _, pt := range n.points {
+		for _, c := range children {
+			if pt.In(c.bounds) {
+				c.points = append(c.points, pt)
+				c.color = Black
+				break
+			}
+		}
+	}
+	n.points = nil
+	n.color = Gray
+
+	for _, c := range children {
+		if len(c.points) > q.capacity {
+			q.subdivide(c)
+		}
+	}
+}
+
+// merge merges the children of n into n if they are all leaves and hold no
+// more than capacity points. merge reports whether the merge happened.
+func (q *PointTree) merge(n *PointNode) bool {
+	total := 0
+	for _, c := range n.c {
+		cn := c.(*PointNode)
+		if cn.color == Gray {
+			return false
+		}
+		total += len(cn.points)
+	}
+	if total > q.capacity {
+		return false
+	}
+
+	n.points = make([]image.Point, 0, total)
+	for i, c := range n.c {
+		n.points = append(n.points, c.(*PointNode).points...)
+		n.c[i] = nil
+	}
+	n.color = White
+	if total > 0 {
+		n.color = Black
+	}
+	return true
+}
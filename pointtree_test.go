@@ -0,0 +1,153 @@
+package rquad
+
+import (
+	"image"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checkPointTree checks the invariants of a PointTree.
+func checkPointTree(t *testing.T, q *PointTree, points []image.Point) {
+	t.Helper()
+
+	var total int
+	q.ForEachLeaf(Gray, func(n Node) {
+		pn := n.(*PointNode)
+		total += len(pn.points)
+		if len(pn.points) > q.capacity && pn.bounds.Dx() > 1 && pn.bounds.Dy() > 1 {
+			t.Fatalf("leaf %v holds %d points, capacity is %d", pn.bounds, len(pn.points), q.capacity)
+		}
+		if (len(pn.points) == 0) != (pn.color == White) {
+			t.Fatalf("leaf %v holds %d points but is %v", pn.bounds, len(pn.points), pn.color)
+		}
+		for _, pt := range pn.points {
+			if !pt.In(pn.bounds) {
+				t.Fatalf("leaf %v holds point %v", pn.bounds, pt)
+			}
+		}
+	})
+	if total != len(points) || q.Len() != len(points) {
+		t.Fatalf("got %d points in leaves, Len() = %d, want %d", total, q.Len(), len(points))
+	}
+	for _, pt := range points {
+		n := Locate(q, pt).(*PointNode)
+		found := false
+		for _, p := range n.points {
+			found = found || p == pt
+		}
+		if !found {
+			t.Fatalf("point %v not found in leaf %v", pt, n.bounds)
+		}
+	}
+}
+
+func TestPointTreeInsertDelete(t *testing.T) {
+	_, err := NewPointTree(image.Rect(0, 0, 10, 10), 0)
+	if err != ErrCapacity {
+		t.Errorf("want ErrCapacity, got %v", err)
+	}
+	_, err = NewPointTree(image.Rect(10, 10, 10, 20), 4)
+	if err != ErrEmptyBounds {
+		t.Errorf("want ErrEmptyBounds, got %v", err)
+	}
+
+	q, err := NewPointTree(image.Rect(0, 0, 100, 80), 4)
+	check(t, err)
+	if loc := q.Root().Location(); loc != Northwest {
+		t.Errorf("root location is %v, want %v, as for region quadtrees", loc, Northwest)
+	}
+
+	r := rand.New(rand.NewSource(99))
+	var points []image.Point
+	for i := 0; i < 500; i++ {
+		pt := image.Pt(r.Intn(100), r.Intn(80))
+		if !q.Insert(pt) {
+			t.Fatalf("can't insert %v", pt)
+		}
+		points = append(points, pt)
+	}
+	// duplicates
+	for i := 0; i < 10; i++ {
+		q.Insert(image.Pt(50, 50))
+		points = append(points, image.Pt(50, 50))
+	}
+	if q.Insert(image.Pt(100, 0)) {
+		t.Errorf("point outside of bounds shouldn't be inserted")
+	}
+	checkPointTree(t, q, points)
+
+	if q.Delete(image.Pt(-1, 0)) {
+		t.Errorf("point outside of bounds shouldn't be deleted")
+	}
+	for len(points) > 0 {
+		i := r.Intn(len(points))
+		if !q.Delete(points[i]) {
+			t.Fatalf("can't delete %v", points[i])
+		}
+		points = append(points[:i], points[i+1:]...)
+		if len(points)%50 == 0 {
+			checkPointTree(t, q, points)
+		}
+	}
+	if q.Root().Color() != White {
+		t.Errorf("empty tree root should be a white leaf, got %v", q.Root().Color())
+	}
+}
+
+func TestPointTreeKNearest(t *testing.T) {
+	q, err := NewPointTree(image.Rect(0, 0, 64, 64), 2)
+	check(t, err)
+
+	r := rand.New(rand.NewSource(99))
+	var points []image.Point
+	for i := 0; i < 200; i++ {
+		pt := image.Pt(r.Intn(64), r.Intn(64))
+		q.Insert(pt)
+		points = append(points, pt)
+	}
+
+	dist2 := func(a, b image.Point) int {
+		d := a.Sub(b)
+		return d.X*d.X + d.Y*d.Y
+	}
+	for i := 0; i < 50; i++ {
+		pt := image.Pt(r.Intn(80)-8, r.Intn(80)-8)
+		k := r.Intn(10) + 1
+		got := q.KNearest(pt, k)
+		if len(got) != k {
+			t.Fatalf("KNearest(%v, %d) returned %d points", pt, k, len(got))
+		}
+
+		// brute force
+		sort.Slice(points, func(i, j int) bool {
+			return dist2(points[i], pt) < dist2(points[j], pt)
+		})
+		for j := range got {
+			if dist2(got[j], pt) != dist2(points[j], pt) {
+				t.Fatalf("KNearest(%v, %d)[%d] = %v, want a point at distance² %d",
+					pt, k, j, got[j], dist2(points[j], pt))
+			}
+		}
+	}
+}
+
+func TestPointTreeNeighbours(t *testing.T) {
+	q, err := NewPointTree(image.Rect(0, 0, 16, 16), 1)
+	check(t, err)
+	q.Insert(image.Pt(1, 1))
+	q.Insert(image.Pt(14, 14))
+	q.Insert(image.Pt(2, 2))
+
+	// NW quadrant is subdivided once more, holding (1,1) and (2,2) in its
+	// own NW quadrant, subdivided again. NE leaf has 2 white western
+	// neighbours and a black southern one.
+	n := Locate(q, image.Pt(12, 2))
+	if n.Bounds() != image.Rect(8, 0, 16, 8) {
+		t.Fatalf("got leaf %v", n.Bounds())
+	}
+	white, black := neighbourColors(n)
+	if white != 2 || black != 1 {
+		t.Errorf("got %d white and %d black neighbours, want 2 and 1", white, black)
+	}
+}
@@ -0,0 +1,58 @@
+package rquad
+
+import (
+	"container/heap"
+	"image"
+)
+
+// queueItem is an element of a priorityQueue, either a node or a point.
+type queueItem struct {
+	node Node        // node, nil if the item is a point
+	pt   image.Point // point, if node is nil
+	dist float64     // priority, lower first
+}
+
+// priorityQueue is a min-heap of queue items, ordered by distance.
+//
+// It implements heap.Interface, though push and pop should be used instead
+// of heap.Push and heap.Pop.
+type priorityQueue []queueItem
+
+func (pq priorityQueue) Len() int            { return len(pq) }
+func (pq priorityQueue) Less(i, j int) bool  { return pq[i].dist < pq[j].dist }
+func (pq priorityQueue) Swap(i, j int)       { pq[i], pq[j] = pq[j], pq[i] }
+func (pq *priorityQueue) Push(x interface{}) { *pq = append(*pq, x.(queueItem)) }
+
+func (pq *priorityQueue) Pop() interface{} {
+	old := *pq
+	item := old[len(old)-1]
+	*pq = old[:len(old)-1]
+	return item
+}
+
+// push adds item to the queue.
+func (pq *priorityQueue) push(item queueItem) {
+	heap.Push(pq, item)
+}
+
+// pop removes and returns the item with the lowest distance.
+func (pq *priorityQueue) pop() queueItem {
+	return heap.Pop(pq).(queueItem)
+}
+
+// rectDist2 returns the squared distance between pt and the closest pixel of
+// r.
+func rectDist2(r image.Rectangle, pt image.Point) float64 {
+	var dx, dy int
+	if pt.X < r.Min.X {
+		dx = r.Min.X - pt.X
+	} else if pt.X >= r.Max.X {
+		dx = pt.X - r.Max.X + 1
+	}
+	if pt.Y < r.Min.Y {
+		dy = r.Min.Y - pt.Y
+	} else if pt.Y >= r.Max.Y {
+		dy = pt.Y - r.Max.Y + 1
+	}
+	return float64(dx*dx + dy*dy)
+}
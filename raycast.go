@@ -0,0 +1,223 @@
+package rquad
+
+import (
+	"image"
+	"math"
+)
+
+// Raycast casts a ray from the center of the pixel from, in direction dir,
+// and returns the first Black leaf it hits, along with the point where the
+// ray enters that leaf. ok is false if the ray travels more than maxDist, or
+// exits the quadtree, without hitting a Black leaf. If from is located in a
+// Black leaf, that leaf is returned, the hit point being the ray origin.
+//
+// dir doesn't need to be normalized, but must not be the zero vector. maxDist
+// is expressed in pixels and can be set to math.Inf(1) for an unlimited
+// range. from must lie inside q.
+//
+// Rather than sampling pixels, Raycast steps from leaf to leaf, looking for
+// the next leaf among the neighbours of the current one, so that its cost
+// only depends on the number of leaves crossed by the ray. A ray passing
+// exactly through the corner shared by 4 leaves goes to the diagonal leaf,
+// without hitting the 2 others.
+func Raycast(q Quadtree, from image.Point, dir FPoint, maxDist float64) (n Node, hit FPoint, ok bool) {
+	return raycast(q, pixelCenter(from), dir, maxDist)
+}
+
+// LineOfSight reports whether the segment joining the centers of the pixels a
+// and b doesn't cross any Black leaf. A segment passing through the corner
+// shared by 2 diagonally adjacent Black leaves is considered as crossing
+// them. a and b must lie inside q.
+func LineOfSight(q Quadtree, a, b image.Point) bool {
+	if a == b {
+		n := Locate(q, a)
+		return n != nil && n.Color() != Black
+	}
+	return segmentClear(q, pixelCenter(a), pixelCenter(b))
+}
+
+// pixelCenter returns the center of the pixel p.
+func pixelCenter(p image.Point) FPoint {
+	return FPoint{float64(p.X) + 0.5, float64(p.Y) + 0.5}
+}
+
+// segmentClear reports whether the segment [a,b] doesn't cross any Black
+// leaf. A horizontal or vertical segment running along a pixel border is
+// considered as crossing the pixels lying on both sides of it, and a segment
+// passing through a pixel corner shared by 2 Black pixels lying on both sides
+// of it as crossing them. A Black leaf only touched by an endpoint of the
+// segment isn't crossed.
+func segmentClear(q Quadtree, a, b FPoint) bool {
+	d := FPoint{b.X - a.X, b.Y - a.Y}
+	l := math.Hypot(d.X, d.Y)
+	sides := []FPoint{d}
+	switch {
+	case d.X == 0:
+		sides = []FPoint{{-1, d.Y}, {1, d.Y}}
+	case d.Y == 0:
+		sides = []FPoint{{d.X, -1}, {d.X, 1}}
+	}
+	for _, side := range sides {
+		if _, _, hit := raycastSide(q, a, d, side, l-epsilon, true); hit {
+			return false
+		}
+	}
+	return true
+}
+
+// raycast casts a ray from o in direction dir. If o lies on a leaf border, the
+// ray starts from the leaf it enters.
+func raycast(q Quadtree, o, dir FPoint, maxDist float64) (Node, FPoint, bool) {
+	return raycastSide(q, o, dir, dir, maxDist, false)
+}
+
+// raycastSide is like raycast, the pixel containing a point lying on a pixel
+// border being chosen in the direction of side, rather than in the direction
+// of the ray. This only matters for rays running along pixel borders.
+//
+// If corners is true, a ray passing through a pixel corner between 2 Black
+// pixels hits them, rather than going to the diagonal pixel.
+func raycastSide(q Quadtree, o, dir, side FPoint, maxDist float64, corners bool) (Node, FPoint, bool) {
+	l := math.Hypot(dir.X, dir.Y)
+	if l == 0 {
+		return nil, FPoint{}, false
+	}
+	d := FPoint{dir.X / l, dir.Y / l}
+
+	n := Locate(q, image.Pt(cellCoord(o.X, side.X), cellCoord(o.Y, side.Y)))
+	t := 0.0
+	for n != nil {
+		if n.Color() == Black {
+			return n, FPoint{o.X + t*d.X, o.Y + t*d.Y}, true
+		}
+
+		// find the side(s) by which the ray exits n
+		b := n.Bounds()
+		tx, ty := exitDist(o.X, d.X, b.Min.X, b.Max.X), exitDist(o.Y, d.Y, b.Min.Y, b.Max.Y)
+		t = math.Min(tx, ty)
+		if t > maxDist {
+			break
+		}
+		p := FPoint{o.X + t*d.X, o.Y + t*d.Y}
+		next := image.Pt(cellCoord(p.X, side.X), cellCoord(p.Y, side.Y))
+		if corners && d.X != 0 && d.Y != 0 && onCorner(p) {
+			m1 := Locate(q, image.Pt(cellCoord(p.X, d.X), cellCoord(p.Y, -d.Y)))
+			m2 := Locate(q, image.Pt(cellCoord(p.X, -d.X), cellCoord(p.Y, d.Y)))
+			if m1 != nil && m2 != nil && m1.Color() == Black && m2.Color() == Black {
+				return m1, p, true
+			}
+		}
+
+		// the exit sides are decided with the same tolerance, in coordinate
+		// units, as the pixel containing the exit point.
+		exitX := onBorder(p.X, d.X, b.Min.X, b.Max.X)
+		exitY := onBorder(p.Y, d.Y, b.Min.Y, b.Max.Y)
+		if !exitX && !exitY {
+			exitX, exitY = tx <= ty, ty <= tx
+		}
+		switch {
+		case exitX && d.X > 0:
+			next.X = b.Max.X
+		case exitX:
+			next.X = b.Min.X - 1
+		}
+		switch {
+		case exitY && d.Y > 0:
+			next.Y = b.Max.Y
+		case exitY:
+			next.Y = b.Min.Y - 1
+		}
+
+		n = nextLeaf(q, n, next, exitX, exitY, d)
+	}
+	return nil, FPoint{}, false
+}
+
+// epsilon is the tolerance used when comparing floating-point coordinates to
+// the integer coordinates of the nodes bounds.
+const epsilon = 1e-9
+
+// cellCoord returns the coordinate of the pixel row or column containing v,
+// when moving along an axis in direction d. If v lies on the border of 2
+// pixels, the one lying in the direction of movement is returned.
+func cellCoord(v, d float64) int {
+	r := math.Round(v)
+	if math.Abs(v-r) < epsilon {
+		if d < 0 {
+			return int(r) - 1
+		}
+		return int(r)
+	}
+	return int(math.Floor(v))
+}
+
+// onBorder reports whether v, moving in direction d along an axis, lies on
+// the border of the interval [min, max] it's about to leave.
+func onBorder(v, d float64, min, max int) bool {
+	switch {
+	case d > 0:
+		return math.Abs(v-float64(max)) < epsilon
+	case d < 0:
+		return math.Abs(v-float64(min)) < epsilon
+	}
+	return false
+}
+
+// onCorner reports whether p lies on a pixel corner.
+func onCorner(p FPoint) bool {
+	return math.Abs(p.X-math.Round(p.X)) < epsilon && math.Abs(p.Y-math.Round(p.Y)) < epsilon
+}
+
+// exitDist returns the distance along a ray starting at o, and moving in
+// direction d along an axis, before it leaves the interval [min, max].
+func exitDist(o, d float64, min, max int) float64 {
+	switch {
+	case d > 0:
+		return (float64(max) - o) / d
+	case d < 0:
+		return (float64(min) - o) / d
+	}
+	return math.Inf(1)
+}
+
+// nextLeaf returns the leaf containing pt, which lies just outside of the
+// leaf n, on the side the ray of direction d exits n. Unless the ray exits by
+// a corner, the leaf is searched among the neighbours of n, falling back to
+// Locate if none of them contains pt.
+func nextLeaf(q Quadtree, n Node, pt image.Point, exitX, exitY bool, d FPoint) Node {
+	if exitX == exitY {
+		return Locate(q, pt)
+	}
+	var side Side
+	switch {
+	case exitX && d.X > 0:
+		side = East
+	case exitX:
+		side = West
+	case d.Y > 0:
+		side = South
+	default:
+		side = North
+	}
+
+	var next Node
+	forEachNeighbourOnSide(n, side, func(nb Node) {
+		if pt.In(nb.Bounds()) {
+			next = nb
+		}
+	})
+	if next == nil {
+		return Locate(q, pt)
+	}
+	return next
+}
+
+// forEachNeighbourOnSide calls fn for each neighbour of n lying on the given
+// side.
+func forEachNeighbourOnSide(n Node, side Side, fn func(Node)) {
+	if cn, ok := n.(*CNNode); ok {
+		cn.forEachNeighbourInDirection(side, fn)
+		return
+	}
+	neighbours(n, side, fn)
+}
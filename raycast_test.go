@@ -0,0 +1,236 @@
+package rquad
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// bruteRaycast returns the distance between a and the first Black leaf of q
+// crossed by the segment [a,b], or +Inf.
+func bruteRaycast(q Quadtree, a, b FPoint) (Node, float64) {
+	var (
+		hit  Node
+		dist = math.Inf(1)
+	)
+	l := math.Hypot(b.X-a.X, b.Y-a.Y)
+	q.ForEachLeaf(Black, func(n Node) {
+		r := toFRect(n.Bounds())
+		if !r.crossedBy(a, b) {
+			return
+		}
+		if t0, _, _ := r.clip(a, b); t0*l < dist {
+			hit, dist = n, t0*l
+		}
+	})
+	return hit, dist
+}
+
+func testRaycast(t *testing.T, impl Implementation) {
+	r := rand.New(rand.NewSource(99))
+	for _, fn := range []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+	} {
+		q := loadTree(t, fn, WithImplementation(impl))
+		for i := 0; i < 200; i++ {
+			from := image.Pt(r.Intn(32), r.Intn(32))
+			angle := r.Float64() * 2 * math.Pi
+			dir := FPoint{math.Cos(angle), math.Sin(angle)}
+			maxDist := math.Inf(1)
+			if i%2 == 0 {
+				maxDist = r.Float64() * 20
+			}
+
+			n, hit, ok := Raycast(q, from, FPoint{3 * dir.X, 3 * dir.Y}, maxDist)
+
+			a := pixelCenter(from)
+			l := math.Min(maxDist, 100)
+			want, dist := bruteRaycast(q, a, FPoint{a.X + l*dir.X, a.Y + l*dir.Y})
+			if ok != (want != nil) {
+				t.Fatalf("%s: Raycast(%v, %v, %v) ok = %t, want %t", fn, from, dir, maxDist, ok, want != nil)
+			}
+			if !ok {
+				continue
+			}
+			if n != want {
+				t.Fatalf("%s: Raycast(%v, %v, %v) hit %v, want %v", fn, from, dir, maxDist, n.Bounds(), want.Bounds())
+			}
+			if got := math.Hypot(hit.X-a.X, hit.Y-a.Y); math.Abs(got-dist) > 1e-6 {
+				t.Fatalf("%s: Raycast(%v, %v, %v) hit at distance %v, want %v", fn, from, dir, maxDist, got, dist)
+			}
+		}
+	}
+}
+
+func TestBasicTreeRaycast(t *testing.T) {
+	testRaycast(t, Basic)
+}
+
+func TestCNTreeRaycast(t *testing.T) {
+	testRaycast(t, CardinalNeighbour)
+}
+
+func testLineOfSight(t *testing.T, impl Implementation) {
+	r := rand.New(rand.NewSource(99))
+	q := loadTree(t, "./testdata/labyrinth2.32x32.png", WithImplementation(impl))
+	var visible int
+	for i := 0; i < 500; i++ {
+		a := image.Pt(r.Intn(32), r.Intn(32))
+		b := image.Pt(r.Intn(32), r.Intn(32))
+		got := LineOfSight(q, a, b)
+		if got != LineOfSight(q, b, a) {
+			t.Fatalf("LineOfSight(%v, %v) should be symmetric", a, b)
+		}
+
+		hit, _ := bruteRaycast(q, pixelCenter(a), pixelCenter(b))
+		if a == b {
+			hit = nil
+			if Locate(q, a).Color() == Black {
+				hit = Locate(q, a)
+			}
+		}
+		if want := hit == nil; got != want {
+			t.Fatalf("LineOfSight(%v, %v) = %t, want %t", a, b, got, want)
+		}
+		if got {
+			visible++
+		}
+	}
+	if visible == 0 {
+		t.Errorf("no visible pair of points, test is useless")
+	}
+}
+
+func TestBasicTreeLineOfSight(t *testing.T) {
+	testLineOfSight(t, Basic)
+}
+
+func TestCNTreeLineOfSight(t *testing.T) {
+	testLineOfSight(t, CardinalNeighbour)
+}
+
+func TestRaycastAxisAligned(t *testing.T) {
+	// a white tree with a black square in the middle
+	q, err := New(NewShapeScanner(image.Rect(0, 0, 16, 16),
+		NewPolygon(FPoint{4, 4}, FPoint{12, 4}, FPoint{12, 12}, FPoint{4, 12})))
+	check(t, err)
+
+	testTbl := []struct {
+		from image.Point
+		dir  FPoint
+		hit  FPoint
+		ok   bool
+	}{
+		{image.Pt(0, 7), FPoint{1, 0}, FPoint{4, 7.5}, true},
+		{image.Pt(15, 7), FPoint{-1, 0}, FPoint{12, 7.5}, true},
+		{image.Pt(7, 0), FPoint{0, 1}, FPoint{7.5, 4}, true},
+		{image.Pt(7, 15), FPoint{0, -1}, FPoint{7.5, 12}, true},
+		{image.Pt(7, 7), FPoint{0, -1}, FPoint{7.5, 7.5}, true},
+		{image.Pt(0, 0), FPoint{1, 0}, FPoint{}, false},
+		{image.Pt(0, 0), FPoint{1, 1}, FPoint{4, 4}, true},
+		{image.Pt(3, 3), FPoint{-1, -1}, FPoint{}, false},
+	}
+	for _, tt := range testTbl {
+		_, hit, ok := Raycast(q, tt.from, tt.dir, math.Inf(1))
+		if ok != tt.ok || math.Abs(hit.X-tt.hit.X) > 1e-9 || math.Abs(hit.Y-tt.hit.Y) > 1e-9 {
+			t.Errorf("Raycast(%v, %v) = %v, %t, want %v, %t", tt.from, tt.dir, hit, ok, tt.hit, tt.ok)
+		}
+	}
+
+	if _, _, ok := Raycast(q, image.Pt(0, 7), FPoint{1, 0}, 3); ok {
+		t.Errorf("Raycast shouldn't hit beyond maxDist")
+	}
+	if _, _, ok := Raycast(q, image.Pt(0, 7), FPoint{1, 0}, 3.5); !ok {
+		t.Errorf("Raycast should hit within maxDist")
+	}
+}
+
+func TestRaycastGrazingCorner(t *testing.T) {
+	// black square at (8,8)-(16,16)
+	q, err := New(NewShapeScanner(image.Rect(0, 0, 16, 16),
+		NewPolygon(FPoint{8, 8}, FPoint{16, 8}, FPoint{16, 16}, FPoint{8, 16})))
+	check(t, err)
+
+	// rays passing within a rounding error of the corner at (8,8)
+	for _, dir := range []FPoint{
+		{7.5, 0.5 - 5e-10},
+		{7.5, 0.5 + 5e-10},
+		{7.5 - 5e-10, 0.5},
+		{7.5 + 5e-10, 0.5},
+	} {
+		n, hit, ok := Raycast(q, image.Pt(0, 7), dir, math.Inf(1))
+		if !ok || n.Color() != Black {
+			t.Errorf("Raycast((0,7), %v) should hit the black square", dir)
+			continue
+		}
+		if math.Abs(hit.X-8) > 1e-6 || math.Abs(hit.Y-8) > 1e-6 {
+			t.Errorf("Raycast((0,7), %v) hit at %v, want (8,8)", dir, hit)
+		}
+	}
+}
+
+func TestSegmentClearAlongBorder(t *testing.T) {
+	testTbl := []struct {
+		side  Side
+		black *Polygon // obstacle touching the line x=8 or y=8
+		a, b  FPoint   // segment running along that line
+	}{
+		{North, NewPolygon(FPoint{0, 4}, FPoint{16, 4}, FPoint{16, 8}, FPoint{0, 8}), FPoint{2, 8}, FPoint{14, 8}},
+		{South, NewPolygon(FPoint{0, 8}, FPoint{16, 8}, FPoint{16, 12}, FPoint{0, 12}), FPoint{2, 8}, FPoint{14, 8}},
+		{West, NewPolygon(FPoint{4, 0}, FPoint{8, 0}, FPoint{8, 16}, FPoint{4, 16}), FPoint{8, 2}, FPoint{8, 14}},
+		{East, NewPolygon(FPoint{8, 0}, FPoint{12, 0}, FPoint{12, 16}, FPoint{8, 16}), FPoint{8, 2}, FPoint{8, 14}},
+	}
+	for _, tt := range testTbl {
+		q, err := New(NewShapeScanner(image.Rect(0, 0, 16, 16), tt.black))
+		check(t, err)
+		// whatever the side of the obstacle and the direction of the segment
+		if segmentClear(q, tt.a, tt.b) || segmentClear(q, tt.b, tt.a) {
+			t.Errorf("obstacle on the %v side: segment %v-%v along its border should be blocked", tt.side, tt.a, tt.b)
+		}
+	}
+}
+
+func TestSegmentClearEndpoints(t *testing.T) {
+	// black NW quadrant
+	q, err := New(NewShapeScanner(image.Rect(0, 0, 8, 8), NewPolygon(FPoint{0, 0}, FPoint{4, 0}, FPoint{4, 4}, FPoint{0, 4})))
+	check(t, err)
+	// segments ending on the border of the black leaf don't cross it
+	for _, tt := range [][2]FPoint{
+		{{6, 6}, {4, 4}},
+		{{6.5, 4}, {4, 4}},
+		{{4, 7}, {4, 4}},
+	} {
+		a, b := tt[0], tt[1]
+		if !segmentClear(q, a, b) || !segmentClear(q, b, a) {
+			t.Errorf("segmentClear(%v, %v) = %t, segmentClear(%v, %v) = %t, want true",
+				a, b, segmentClear(q, a, b), b, a, segmentClear(q, b, a))
+		}
+	}
+}
+
+func TestLineOfSightDiagonalPinch(t *testing.T) {
+	// 2 black squares touching by their corner at (8,8)
+	q, err := New(NewShapeScanner(image.Rect(0, 0, 16, 16),
+		NewPolygon(FPoint{8, 4}, FPoint{12, 4}, FPoint{12, 8}, FPoint{8, 8}),
+		NewPolygon(FPoint{4, 8}, FPoint{8, 8}, FPoint{8, 12}, FPoint{4, 12}),
+	))
+	check(t, err)
+	for _, tt := range []struct {
+		a, b image.Point
+		want bool
+	}{
+		{image.Pt(6, 6), image.Pt(9, 9), false},
+		{image.Pt(2, 2), image.Pt(13, 13), false},
+		{image.Pt(6, 6), image.Pt(1, 1), true},
+	} {
+		if got := LineOfSight(q, tt.a, tt.b); got != tt.want {
+			t.Errorf("LineOfSight(%v, %v) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+		if got := LineOfSight(q, tt.b, tt.a); got != tt.want {
+			t.Errorf("LineOfSight(%v, %v) = %t, want %t", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
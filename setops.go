@@ -0,0 +1,141 @@
+package rquad
+
+import (
+	"errors"
+	"image"
+)
+
+// ErrBounds is returned by operations involving many quadtrees, when they
+// don't have the same bounds.
+var ErrBounds = errors.New("quadtrees must have the same bounds")
+
+// setOp is a boolean operation on sets, the operands being true when
+// a point belongs to the set.
+type setOp func(a, b bool) bool
+
+// Union returns a new quadtree which Black region is the union of the Black
+// regions of a and b.
+//
+// Set operations consider that the set represented by a quadtree is its Black
+// region, that is the obstructed area. The returned quadtree has the same
+// implementation and creation options as a, it is computed by walking both
+// quadtrees simultaneously, and then compacted: no Gray node has 4 leaf
+// children of the same color. Set operations return ErrBounds if a and b
+// don't have the same bounds.
+func Union(a, b Quadtree) (Quadtree, error) {
+	return combine(a, b, func(a, b bool) bool { return a || b })
+}
+
+// Intersection returns a new quadtree which Black region is the intersection
+// of the Black regions of a and b.
+//
+// See Union for the details common to all set operations.
+func Intersection(a, b Quadtree) (Quadtree, error) {
+	return combine(a, b, func(a, b bool) bool { return a && b })
+}
+
+// Difference returns a new quadtree which Black region is the Black region of
+// a minus the Black region of b.
+//
+// See Union for the details common to all set operations.
+func Difference(a, b Quadtree) (Quadtree, error) {
+	return combine(a, b, func(a, b bool) bool { return a && !b })
+}
+
+// XOR returns a new quadtree which Black region is made of the regions that
+// are Black in either a or b, but not in both.
+//
+// See Union for the details common to all set operations.
+func XOR(a, b Quadtree) (Quadtree, error) {
+	return combine(a, b, func(a, b bool) bool { return a != b })
+}
+
+// Complement returns a new quadtree in which the colors of the leaves of q
+// are swapped, Black becoming White and vice versa.
+//
+// The returned quadtree has the same implementation and creation options as
+// q.
+func Complement(q Quadtree) Quadtree {
+	root := complementNode(q.Root(), nil)
+	return newTree(implementationOf(q), root, optionsOf(q))
+}
+
+// combine walks simultaneously a and b and returns a new quadtree resulting
+// from the application of op on each region.
+func combine(a, b Quadtree, op setOp) (Quadtree, error) {
+	bounds := a.Root().Bounds()
+	if bounds != b.Root().Bounds() {
+		return nil, ErrBounds
+	}
+	root := combineNodes(a.Root(), b.Root(), bounds, nil, op)
+	return newTree(implementationOf(a), root, optionsOf(a)), nil
+}
+
+// combineNodes returns the node of given bounds resulting of the application
+// of op on a and b, which are either leaves covering bounds, or gray nodes of
+// the same bounds.
+func combineNodes(a, b Node, bounds image.Rectangle, parent *BasicNode, op setOp) *BasicNode {
+	n := &BasicNode{
+		bounds: bounds,
+		color:  Gray,
+	}
+	if parent != nil {
+		n.parent = parent
+	}
+
+	ca, cb := a.Color(), b.Color()
+	switch {
+	case ca != Gray && cb != Gray:
+		n.color = opColor(op, ca, cb)
+		return n
+	case ca != Gray && op(ca == Black, false) == op(ca == Black, true):
+		// result doesn't depend on b
+		n.color = opColor(op, ca, White)
+		return n
+	case cb != Gray && op(false, cb == Black) == op(true, cb == Black):
+		// result doesn't depend on a
+		n.color = opColor(op, White, cb)
+		return n
+	}
+
+	for q, qb := range quadrants(bounds) {
+		c := combineNodes(subNode(a, Quadrant(q)), subNode(b, Quadrant(q)), qb, n, op)
+		c.location = Quadrant(q)
+		n.c[q] = c
+	}
+	compactNode(n)
+	return n
+}
+
+// opColor returns the color resulting of the application of op on the colors
+// of 2 leaves.
+func opColor(op setOp, a, b Color) Color {
+	if op(a == Black, b == Black) {
+		return Black
+	}
+	return White
+}
+
+// complementNode returns a copy of the subtree rooted at n, in which leaf
+// colors are swapped.
+func complementNode(n Node, parent *BasicNode) *BasicNode {
+	cn := &BasicNode{
+		bounds:   n.Bounds(),
+		location: n.Location(),
+	}
+	if parent != nil {
+		cn.parent = parent
+	}
+	switch n.Color() {
+	case Black:
+		cn.color = White
+	case White:
+		cn.color = Black
+	case Gray:
+		cn.color = Gray
+		for q := range cn.c {
+			cn.c[q] = complementNode(n.Child(Quadrant(q)), cn)
+		}
+	}
+	return cn
+}
@@ -0,0 +1,70 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+)
+
+func testSetOperations(t *testing.T, impl Implementation) {
+	files := []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+	}
+
+	var testTbl = []struct {
+		name string
+		fn   func(a, b Quadtree) (Quadtree, error)
+		op   setOp
+	}{
+		{"Union", Union, func(a, b bool) bool { return a || b }},
+		{"Intersection", Intersection, func(a, b bool) bool { return a && b }},
+		{"Difference", Difference, func(a, b bool) bool { return a && !b }},
+		{"XOR", XOR, func(a, b bool) bool { return a != b }},
+		{"Complement", func(a, b Quadtree) (Quadtree, error) { return Complement(a), nil },
+			func(a, b bool) bool { return !a }},
+	}
+
+	for _, fa := range files {
+		a := loadTree(t, fa, WithImplementation(impl))
+		for _, fb := range files {
+			b := loadTree(t, fb, WithImplementation(impl), WithResolution(2))
+			for _, tt := range testTbl {
+				res, err := tt.fn(a, b)
+				check(t, err)
+				if implementationOf(res) != impl {
+					t.Fatalf("%s: got implementation %v, want %v", tt.name, implementationOf(res), impl)
+				}
+
+				bounds := a.Root().Bounds()
+				for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+					for x := bounds.Min.X; x < bounds.Max.X; x++ {
+						pt := image.Pt(x, y)
+						want := opColor(tt.op, Locate(a, pt).Color(), Locate(b, pt).Color())
+						if got := Locate(res, pt).Color(); got != want {
+							t.Fatalf("%s(%s, %s) at %v: got %v, want %v", tt.name, fa, fb, pt, got, want)
+						}
+					}
+				}
+				checkCompact(t, res.Root())
+				checkNeighbours(t, res)
+			}
+		}
+	}
+}
+
+func TestBasicTreeSetOperations(t *testing.T) {
+	testSetOperations(t, Basic)
+}
+
+func TestCNTreeSetOperations(t *testing.T) {
+	testSetOperations(t, CardinalNeighbour)
+}
+
+func TestSetOperationsBounds(t *testing.T) {
+	a := loadTree(t, "./testdata/labyrinth1.32x32.png")
+	b := loadTree(t, "./testdata/labyrinth4.8x8.png")
+	if _, err := Union(a, b); err != ErrBounds {
+		t.Errorf("want ErrBounds, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package rquad
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+// ShapeScanner is an imgscan.Scanner of a rectangular area made of vector
+// geometry, it allows to create quadtrees out of vector data, without having
+// to rasterize it first.
+//
+// Areas covered by shapes are Black, the rest is White. Uniformity of a
+// region is decided by exact geometric intersection tests, so leaves are
+// aligned on the geometry, and the cost of large empty areas is independent of
+// the resolution.
+//
+// A region covered by the union of many shapes, but not by any of them
+// individually, is considered as not uniform. At maximum resolution, such a
+// region becomes a Black leaf anyway.
+type ShapeScanner struct {
+	bounds image.Rectangle
+	shapes []Shape
+}
+
+// NewShapeScanner returns a ShapeScanner of the area delimited by bounds, in
+// which shapes represent the obstructed regions.
+func NewShapeScanner(bounds image.Rectangle, shapes ...Shape) *ShapeScanner {
+	return &ShapeScanner{bounds: bounds, shapes: shapes}
+}
+
+// ColorModel returns the binimg.Model color model.
+func (s *ShapeScanner) ColorModel() color.Model {
+	return binimg.Model
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (s *ShapeScanner) Bounds() image.Rectangle {
+	return s.bounds
+}
+
+// At returns the color of the pixel at (x, y), it's binimg.Black if any shape
+// overlaps the pixel, binimg.White otherwise.
+func (s *ShapeScanner) At(x, y int) color.Color {
+	pt := image.Pt(x, y)
+	if !pt.In(s.bounds) {
+		return binimg.Bit{}
+	}
+	r := image.Rectangle{pt, pt.Add(image.Pt(1, 1))}
+	for _, shape := range s.shapes {
+		if shape.Overlaps(r) {
+			return binimg.Black
+		}
+	}
+	return binimg.White
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+func (s *ShapeScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	uniform, col := s.IsUniform(r)
+	return uniform && col == binimg.Model.Convert(c)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+func (s *ShapeScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	overlapped := false
+	for _, shape := range s.shapes {
+		if shape.Covers(r) {
+			return true, binimg.Black
+		}
+		if !overlapped && shape.Overlaps(r) {
+			overlapped = true
+		}
+	}
+	if overlapped {
+		return false, nil
+	}
+	return true, binimg.White
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. Just as with binary images scanners, the average color of a
+// non-uniform region is binimg.On.
+func (s *ShapeScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+	return false, binimg.On
+}
@@ -0,0 +1,143 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+	"github.com/arl/imgtools/imgscan"
+)
+
+func TestShapesIntersection(t *testing.T) {
+	square := NewPolygon(FPoint{4, 4}, FPoint{12, 4}, FPoint{12, 12}, FPoint{4, 12})
+	triangle := NewPolygon(FPoint{0, 0}, FPoint{16, 0}, FPoint{0, 16})
+	// concave 'U' shape, open on the north
+	ushape := NewPolygon(FPoint{0, 0}, FPoint{4, 0}, FPoint{4, 12}, FPoint{12, 12},
+		FPoint{12, 0}, FPoint{16, 0}, FPoint{16, 16}, FPoint{0, 16})
+	line := NewPolyline(2, FPoint{0, 8}, FPoint{16, 8})
+	disc := NewPolyline(8, FPoint{8, 8})
+
+	var testTbl = []struct {
+		shape    Shape
+		r        image.Rectangle
+		overlaps bool
+		covers   bool
+	}{
+		{square, image.Rect(4, 4, 12, 12), true, true},
+		{square, image.Rect(5, 5, 6, 6), true, true},
+		{square, image.Rect(0, 0, 16, 16), true, false},
+		{square, image.Rect(0, 0, 4, 4), false, false},
+		{square, image.Rect(0, 4, 4, 12), false, false},
+		{square, image.Rect(11, 11, 13, 13), true, false},
+		{triangle, image.Rect(0, 0, 4, 4), true, true},
+		{triangle, image.Rect(7, 7, 8, 8), true, true},
+		{triangle, image.Rect(8, 8, 9, 9), false, false},
+		{triangle, image.Rect(7, 7, 9, 9), true, false},
+		{ushape, image.Rect(6, 2, 10, 10), false, false},
+		{ushape, image.Rect(0, 12, 16, 16), true, true},
+		{ushape, image.Rect(3, 3, 5, 5), true, false},
+		{line, image.Rect(0, 7, 16, 9), true, true},
+		{line, image.Rect(0, 0, 16, 7), false, false},
+		{line, image.Rect(4, 6, 5, 8), true, false},
+		{disc, image.Rect(6, 6, 10, 10), true, true},
+		{disc, image.Rect(0, 0, 4, 4), false, false},
+		{disc, image.Rect(4, 4, 12, 12), true, false},
+		{disc, image.Rect(12, 0, 16, 16), false, false},
+	}
+
+	for i, tt := range testTbl {
+		if got := tt.shape.Overlaps(tt.r); got != tt.overlaps {
+			t.Errorf("test %d: Overlaps(%v) = %t, want %t", i, tt.r, got, tt.overlaps)
+		}
+		if got := tt.shape.Covers(tt.r); got != tt.covers {
+			t.Errorf("test %d: Covers(%v) = %t, want %t", i, tt.r, got, tt.covers)
+		}
+	}
+}
+
+// rasterize returns a binary image of the same bounds as scanner in which each
+// pixel has the color returned by scanner.At.
+func rasterize(scanner imgscan.Scanner) *binimg.Image {
+	b := scanner.Bounds()
+	img := binimg.New(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			img.Set(x, y, scanner.At(x, y))
+		}
+	}
+	return img
+}
+
+func testShapeScannerTree(t *testing.T, impl Implementation) {
+	bounds := image.Rect(0, 0, 64, 64)
+	var testTbl = []struct {
+		name    string
+		shapes  []Shape
+		aligned bool // shapes are aligned on pixels
+	}{
+		{
+			"square",
+			[]Shape{NewPolygon(FPoint{8, 8}, FPoint{24, 8}, FPoint{24, 40}, FPoint{8, 40})},
+			true,
+		},
+		{
+			"rectangles",
+			[]Shape{
+				NewPolygon(FPoint{0, 0}, FPoint{64, 0}, FPoint{64, 3}, FPoint{0, 3}),
+				NewPolygon(FPoint{30, 10}, FPoint{33, 10}, FPoint{33, 50}, FPoint{30, 50}),
+			},
+			true,
+		},
+		{
+			"diagonal",
+			[]Shape{
+				NewPolygon(FPoint{10.5, 3}, FPoint{60, 40.2}, FPoint{20, 61}),
+				NewPolyline(3, FPoint{0, 60}, FPoint{30, 30}, FPoint{63, 20}),
+			},
+			false,
+		},
+	}
+
+	for _, tt := range testTbl {
+		scanner := NewShapeScanner(bounds, tt.shapes...)
+		q, err := New(scanner, WithImplementation(impl))
+		check(t, err)
+
+		// the rasterized shape must have the same coverage
+		rscanner, err := imgscan.NewScanner(rasterize(scanner))
+		check(t, err)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				want := Black
+				if rscanner.At(x, y) == binimg.White {
+					want = White
+				}
+				if got := Locate(q, image.Pt(x, y)).Color(); got != want {
+					t.Fatalf("%s: pixel (%d,%d) got %v, want %v", tt.name, x, y, got, want)
+				}
+			}
+		}
+
+		if !tt.aligned {
+			continue
+		}
+
+		// shapes aligned on pixels must produce the same decomposition
+		rq, err := New(rscanner, WithImplementation(impl))
+		check(t, err)
+		var leaves, rleaves int
+		q.ForEachLeaf(Gray, func(Node) { leaves++ })
+		rq.ForEachLeaf(Gray, func(Node) { rleaves++ })
+		if leaves != rleaves {
+			t.Errorf("%s: got %d leaves, want %d", tt.name, leaves, rleaves)
+		}
+	}
+}
+
+func TestBasicTreeShapeScanner(t *testing.T) {
+	testShapeScannerTree(t, Basic)
+}
+
+func TestCNTreeShapeScanner(t *testing.T) {
+	testShapeScannerTree(t, CardinalNeighbour)
+}
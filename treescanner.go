@@ -0,0 +1,82 @@
+package rquad
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+// TreeScanner is an imgscan.Scanner of an existing quadtree, it allows to
+// create quadtrees out of another one, for example to convert it to another
+// implementation, to create it again with different options, or to crop it,
+// without having to go back to the source image.
+//
+// The uniformity of a region is decided by IsRegionUniform, that is by
+// descending the quadtree hierarchy rather than by scanning pixels.
+type TreeScanner struct {
+	q      Quadtree
+	bounds image.Rectangle
+}
+
+// NewTreeScanner returns a TreeScanner of q, which bounds are the bounds of
+// the root of q.
+func NewTreeScanner(q Quadtree) *TreeScanner {
+	return &TreeScanner{q: q, bounds: q.Root().Bounds()}
+}
+
+// Crop returns a TreeScanner of the same quadtree, restricted to the pixels
+// lying in both r and the bounds of s. As with image.SubImage, the pixels
+// keep their coordinates.
+func (s *TreeScanner) Crop(r image.Rectangle) *TreeScanner {
+	return &TreeScanner{q: s.q, bounds: r.Intersect(s.bounds)}
+}
+
+// ColorModel returns the binimg.Model color model.
+func (s *TreeScanner) ColorModel() color.Model {
+	return binimg.Model
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+func (s *TreeScanner) Bounds() image.Rectangle {
+	return s.bounds
+}
+
+// At returns the color of the pixel at (x, y), binimg.Black or binimg.White
+// depending on the color of the leaf containing it.
+func (s *TreeScanner) At(x, y int) color.Color {
+	pt := image.Pt(x, y)
+	if !pt.In(s.bounds) {
+		return binimg.Bit{}
+	}
+	return leafColor(Locate(s.q, pt))
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+func (s *TreeScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	uniform, col := s.IsUniform(r)
+	return uniform && col == binimg.Model.Convert(c)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+func (s *TreeScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	if !r.In(s.bounds) {
+		return false, nil
+	}
+	uniform, col := IsRegionUniform(s.q, r)
+	if !uniform {
+		return false, nil
+	}
+	return true, bit(col)
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. Just as with binary images scanners, the average color of a
+// non-uniform region is binimg.On.
+func (s *TreeScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+	return false, binimg.On
+}
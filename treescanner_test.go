@@ -0,0 +1,94 @@
+package rquad
+
+import (
+	"image"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+	"github.com/arl/imgtools/imgscan"
+)
+
+var _ imgscan.Scanner = (*TreeScanner)(nil)
+
+func TestTreeScannerRebuild(t *testing.T) {
+	for _, file := range []string{
+		"./testdata/labyrinth1.32x32.png",
+		"./testdata/labyrinth2.32x32.png",
+		"./testdata/labyrinth3.32x32.png",
+	} {
+		q := loadTree(t, file)
+
+		// convert to another implementation
+		cn, err := New(NewTreeScanner(q), WithImplementation(CardinalNeighbour))
+		check(t, err)
+		if _, ok := cn.(*CNTree); !ok || !Equal(q, cn) {
+			t.Errorf("%s: converted quadtree differs from the original", file)
+		}
+		checkNeighbours(t, cn)
+
+		// rebuild at a coarser resolution
+		for _, res := range []int{2, 4, 8} {
+			coarse, err := New(NewTreeScanner(cn), WithResolution(res))
+			check(t, err)
+			if want := loadTree(t, file, WithResolution(res)); !Equal(coarse, want) {
+				t.Errorf("%s: quadtree rebuilt at resolution %d differs from the one created from the image", file, res)
+			}
+		}
+	}
+}
+
+func TestTreeScannerCrop(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth2.32x32.png")
+	crop := image.Rect(16, 0, 32, 16)
+	s := NewTreeScanner(q).Crop(image.Rect(16, -8, 48, 16))
+	if s.Bounds() != crop {
+		t.Fatalf("got bounds %v, want %v", s.Bounds(), crop)
+	}
+	cropped, err := New(s, WithImplementation(CardinalNeighbour))
+	check(t, err)
+	if cropped.Root().Bounds() != crop {
+		t.Fatalf("got root bounds %v, want %v", cropped.Root().Bounds(), crop)
+	}
+	for y := crop.Min.Y; y < crop.Max.Y; y++ {
+		for x := crop.Min.X; x < crop.Max.X; x++ {
+			pt := image.Pt(x, y)
+			if got, want := pixelColor(cropped, pt), pixelColor(q, pt); got != want {
+				t.Fatalf("pixel %v is %v, want %v", pt, got, want)
+			}
+		}
+	}
+	if s.At(0, 0) != (binimg.Bit{}) {
+		t.Errorf("pixel outside of bounds should have the zero color")
+	}
+}
+
+func TestTreeScannerUniformity(t *testing.T) {
+	q, err := New(NewShapeScanner(image.Rect(0, 0, 16, 16),
+		NewPolygon(FPoint{0, 0}, FPoint{8, 0}, FPoint{8, 8}, FPoint{0, 8})))
+	check(t, err)
+	s := NewTreeScanner(q)
+
+	testTbl := []struct {
+		r       image.Rectangle
+		uniform bool
+		col     binimg.Bit
+	}{
+		{image.Rect(0, 0, 8, 8), true, binimg.Black},
+		{image.Rect(2, 3, 5, 7), true, binimg.Black},
+		{image.Rect(8, 0, 16, 16), true, binimg.White},
+		{image.Rect(4, 4, 12, 12), false, binimg.On},
+		{image.Rect(8, 8, 20, 20), false, binimg.On},
+	}
+	for _, tt := range testTbl {
+		uniform, col := s.IsUniform(tt.r)
+		if uniform != tt.uniform || (uniform && col != tt.col) || (!uniform && col != nil) {
+			t.Errorf("IsUniform(%v) = %t, %v, want %t, %v", tt.r, uniform, col, tt.uniform, tt.col)
+		}
+		if got := s.IsUniformColor(tt.r, tt.col); got != tt.uniform {
+			t.Errorf("IsUniformColor(%v, %v) = %t, want %t", tt.r, tt.col, got, tt.uniform)
+		}
+		if uniform, col := s.AverageColor(tt.r); uniform != tt.uniform || col != tt.col {
+			t.Errorf("AverageColor(%v) = %t, %v, want %t, %v", tt.r, uniform, col, tt.uniform, tt.col)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package rquad
+
+import (
+	"image"
+	"math"
+)
+
+// IsRegionUniform reports whether all the pixels of the rectangular region r
+// have the same color, and returns that color. If the region isn't uniform,
+// or doesn't lie entirely inside q, IsRegionUniform returns false and Gray.
+//
+// IsRegionUniform descends the quadtree from the root, only visiting the
+// nodes overlapping r. It returns as soon as it finds a leaf containing r, or
+// 2 leaves of different colors, so that checking whether a robot footprint is
+// free of obstacles is generally much faster than locating each of its
+// pixels.
+func IsRegionUniform(q Quadtree, r image.Rectangle) (bool, Color) {
+	root := q.Root()
+	if r.Empty() || !r.In(root.Bounds()) {
+		return false, Gray
+	}
+	col := Gray
+	uniform := forEachLeafUntil(root, r.Overlaps, func(n Node) bool {
+		if col == Gray {
+			col = n.Color()
+		}
+		return n.Color() == col
+	})
+	if !uniform {
+		return false, Gray
+	}
+	return true, col
+}
+
+// IsShapeUniform reports whether all the leaves of q overlapped by the shape s
+// have the same color, and returns that color. Parts of s lying outside of q
+// are not considered. If the leaves overlapped by s have different colors,
+// or if s doesn't overlap q, IsShapeUniform returns false and Gray.
+//
+// As IsRegionUniform, IsShapeUniform descends from the root, only visiting
+// the nodes overlapped by s, and returns as soon as it finds 2 leaves of
+// different colors.
+func IsShapeUniform(q Quadtree, s Shape) (bool, Color) {
+	col := Gray
+	uniform := forEachLeafUntil(q.Root(), s.Overlaps, func(n Node) bool {
+		if col == Gray {
+			col = n.Color()
+		}
+		return n.Color() == col
+	})
+	if !uniform || col == Gray {
+		return false, Gray
+	}
+	return true, col
+}
+
+// IsDiscUniform is like IsShapeUniform, for the disc of given center and
+// radius.
+func IsDiscUniform(q Quadtree, center FPoint, radius float64) (bool, Color) {
+	return IsShapeUniform(q, NewPolyline(2*radius, center))
+}
+
+// IsOrientedRectUniform is like IsShapeUniform, for the rectangle of given
+// center and size, rotated by angle radians around its center. Considering
+// the y axis pointing downwards, as it does in images, a positive angle
+// rotates the rectangle clockwise on screen.
+func IsOrientedRectUniform(q Quadtree, center FPoint, width, height, angle float64) (bool, Color) {
+	sin, cos := math.Sincos(angle)
+	corner := func(x, y float64) FPoint {
+		return FPoint{
+			X: center.X + x*cos - y*sin,
+			Y: center.Y + x*sin + y*cos,
+		}
+	}
+	w, h := width/2, height/2
+	return IsShapeUniform(q, NewPolygon(
+		corner(-w, -h), corner(w, -h), corner(w, h), corner(-w, h),
+	))
+}
+
+// forEachLeafUntil calls fn for each leaf of the subtree rooted at n for
+// which, as for all its ancestors, visit returns true when passed the node
+// bounds. It stops as soon as fn returns false, in which case
+// forEachLeafUntil also returns false, otherwise it returns true.
+func forEachLeafUntil(n Node, visit func(image.Rectangle) bool, fn func(Node) bool) bool {
+	if !visit(n.Bounds()) {
+		return true
+	}
+	if n.Color() != Gray {
+		return fn(n)
+	}
+	for q := Northwest; q <= Southeast; q++ {
+		if !forEachLeafUntil(n.Child(q), visit, fn) {
+			return false
+		}
+	}
+	return true
+}
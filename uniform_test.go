@@ -0,0 +1,167 @@
+package rquad
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/go-rquad/internal"
+	"github.com/arl/imgtools/imgscan"
+)
+
+func testIsRegionUniform(t *testing.T, impl Implementation) {
+	q := loadTree(t, "./testdata/labyrinth2.32x32.png", WithImplementation(impl))
+	r := rand.New(rand.NewSource(99))
+	var uniform int
+	for i := 0; i < 500; i++ {
+		x, y := r.Intn(32), r.Intn(32)
+		rect := image.Rect(x, y, x+r.Intn(6)+1, y+r.Intn(6)+1)
+
+		wantOk, wantCol := rect.In(q.Root().Bounds()), Gray
+		if wantOk {
+			wantCol = pixelColor(q, rect.Min)
+			for y := rect.Min.Y; y < rect.Max.Y; y++ {
+				for x := rect.Min.X; x < rect.Max.X; x++ {
+					if pixelColor(q, image.Pt(x, y)) != wantCol {
+						wantOk, wantCol = false, Gray
+					}
+				}
+			}
+		}
+
+		ok, col := IsRegionUniform(q, rect)
+		if ok != wantOk || col != wantCol {
+			t.Fatalf("IsRegionUniform(%v) = %t, %v, want %t, %v", rect, ok, col, wantOk, wantCol)
+		}
+		if ok {
+			uniform++
+		}
+	}
+	if uniform == 0 {
+		t.Errorf("no uniform region, test is useless")
+	}
+}
+
+func TestBasicTreeIsRegionUniform(t *testing.T) {
+	testIsRegionUniform(t, Basic)
+}
+
+func TestCNTreeIsRegionUniform(t *testing.T) {
+	testIsRegionUniform(t, CardinalNeighbour)
+}
+
+// bruteShapeUniform checks the colors of all the leaves overlapped by s.
+func bruteShapeUniform(q Quadtree, s Shape) (bool, Color) {
+	col := Gray
+	ok := true
+	q.ForEachLeaf(Gray, func(n Node) {
+		if !s.Overlaps(n.Bounds()) {
+			return
+		}
+		if col == Gray {
+			col = n.Color()
+		}
+		ok = ok && n.Color() == col
+	})
+	if !ok || col == Gray {
+		return false, Gray
+	}
+	return true, col
+}
+
+func TestIsShapeUniform(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth3.32x32.png")
+	r := rand.New(rand.NewSource(99))
+	for i := 0; i < 500; i++ {
+		center := FPoint{r.Float64()*40 - 4, r.Float64()*40 - 4}
+		radius := r.Float64() * 4
+		w, h, angle := r.Float64()*8, r.Float64()*8, r.Float64()*math.Pi
+
+		ok, col := IsDiscUniform(q, center, radius)
+		wantOk, wantCol := bruteShapeUniform(q, NewPolyline(2*radius, center))
+		if ok != wantOk || col != wantCol {
+			t.Fatalf("IsDiscUniform(%v, %v) = %t, %v, want %t, %v", center, radius, ok, col, wantOk, wantCol)
+		}
+
+		ok, col = IsOrientedRectUniform(q, center, w, h, angle)
+		// the rectangle sides, rotated by angle, clockwise since y points
+		// downwards.
+		u := FPoint{math.Cos(angle) * w / 2, math.Sin(angle) * w / 2}
+		v := FPoint{-math.Sin(angle) * h / 2, math.Cos(angle) * h / 2}
+		wantOk, wantCol = bruteShapeUniform(q, NewPolygon(
+			FPoint{center.X - u.X - v.X, center.Y - u.Y - v.Y},
+			FPoint{center.X + u.X - v.X, center.Y + u.Y - v.Y},
+			FPoint{center.X + u.X + v.X, center.Y + u.Y + v.Y},
+			FPoint{center.X - u.X + v.X, center.Y - u.Y + v.Y},
+		))
+		if ok != wantOk || col != wantCol {
+			t.Fatalf("IsOrientedRectUniform(%v, %v, %v, %v) = %t, %v, want %t, %v",
+				center, w, h, angle, ok, col, wantOk, wantCol)
+		}
+	}
+
+	// axis-aligned rectangle
+	ok, col := IsOrientedRectUniform(q, FPoint{4, 4}, 2, 6, 0)
+	wantOk, wantCol := bruteShapeUniform(q, NewPolygon(FPoint{3, 1}, FPoint{5, 1}, FPoint{5, 7}, FPoint{3, 7}))
+	if ok != wantOk || col != wantCol {
+		t.Errorf("IsOrientedRectUniform = %t, %v, want %t, %v", ok, col, wantOk, wantCol)
+	}
+
+	// the same rectangle, lying horizontally, rotated by a quarter turn
+	ok, col = IsOrientedRectUniform(q, FPoint{4, 4}, 6, 2, math.Pi/2)
+	wantOk, wantCol = bruteShapeUniform(q, NewPolygon(FPoint{3, 1}, FPoint{5, 1}, FPoint{5, 7}, FPoint{3, 7}))
+	if ok != wantOk || col != wantCol {
+		t.Errorf("IsOrientedRectUniform = %t, %v, want %t, %v", ok, col, wantOk, wantCol)
+	}
+
+	// outside of the quadtree
+	if ok, col := IsDiscUniform(q, FPoint{-10, -10}, 2); ok || col != Gray {
+		t.Errorf("disc outside of the quadtree: got %t, %v, want false, Gray", ok, col)
+	}
+}
+
+func benchmarkRegionUniform(b *testing.B, fn func(Quadtree, image.Rectangle) bool) {
+	img, err := internal.LoadPNG("./testdata/bigsquare.png")
+	checkB(b, err)
+	scanner, err := imgscan.NewScanner(img)
+	checkB(b, err)
+	q, err := New(scanner)
+	checkB(b, err)
+
+	r := rand.New(rand.NewSource(99))
+	bounds := q.Root().Bounds()
+	rects := make([]image.Rectangle, 100)
+	for i := range rects {
+		x, y := r.Intn(bounds.Dx()-16), r.Intn(bounds.Dy()-16)
+		rects[i] = image.Rect(x, y, x+16, y+16)
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, rect := range rects {
+			fn(q, rect)
+		}
+	}
+}
+
+func BenchmarkIsRegionUniform(b *testing.B) {
+	benchmarkRegionUniform(b, func(q Quadtree, r image.Rectangle) bool {
+		ok, _ := IsRegionUniform(q, r)
+		return ok
+	})
+}
+
+func BenchmarkRegionUniformLocate(b *testing.B) {
+	benchmarkRegionUniform(b, func(q Quadtree, r image.Rectangle) bool {
+		col := Locate(q, r.Min).Color()
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				if Locate(q, image.Pt(x, y)).Color() != col {
+					return false
+				}
+			}
+		}
+		return true
+	})
+}
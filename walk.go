@@ -0,0 +1,105 @@
+package rquad
+
+import "errors"
+
+// WalkOrder is the order in which Walk visits the nodes.
+type WalkOrder int
+
+// Possible values for the WalkOrder type.
+const (
+	// PreOrder visits a node before its children.
+	PreOrder WalkOrder = iota
+
+	// PostOrder visits a node after its children.
+	PostOrder
+
+	// BreadthFirst visits the nodes level by level, starting from the root.
+	BreadthFirst
+)
+
+var (
+	// SkipSubtree is used as a return value from WalkFuncs to indicate that
+	// the children of the node passed to the function are to be skipped. It
+	// is not returned as an error by Walk, and has no effect in PostOrder,
+	// in which children are visited first.
+	SkipSubtree = errors.New("skip this subtree")
+
+	// StopWalk is used as a return value from WalkFuncs to indicate that
+	// the walk must stop. It is not returned as an error by Walk.
+	StopWalk = errors.New("stop the walk")
+)
+
+// WalkFunc is the type of the function called by Walk for each visited
+// node. If it returns an error, other than SkipSubtree, the walk stops and
+// Walk returns that error, unless it's StopWalk.
+type WalkFunc func(n Node) error
+
+// Walk walks the subtree rooted at root, calling fn for each node, Gray
+// nodes included, in the given order. Children are visited in Z-order,
+// that is Northwest, Northeast, Southwest and Southeast.
+func Walk(root Node, order WalkOrder, fn WalkFunc) error {
+	var err error
+	switch order {
+	case PreOrder:
+		err = walkPreOrder(root, fn)
+	case PostOrder:
+		err = walkPostOrder(root, fn)
+	default:
+		err = walkBreadthFirst(root, fn)
+	}
+	if err == StopWalk {
+		return nil
+	}
+	return err
+}
+
+func walkPreOrder(n Node, fn WalkFunc) error {
+	err := fn(n)
+	if err == SkipSubtree {
+		return nil
+	}
+	if err != nil || n.Color() != Gray {
+		return err
+	}
+	for q := Northwest; q <= Southeast; q++ {
+		if err := walkPreOrder(n.Child(q), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkPostOrder(n Node, fn WalkFunc) error {
+	if n.Color() == Gray {
+		for q := Northwest; q <= Southeast; q++ {
+			if err := walkPostOrder(n.Child(q), fn); err != nil {
+				return err
+			}
+		}
+	}
+	if err := fn(n); err != SkipSubtree {
+		return err
+	}
+	return nil
+}
+
+func walkBreadthFirst(root Node, fn WalkFunc) error {
+	queue := []Node{root}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		err := fn(n)
+		if err == SkipSubtree {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if n.Color() == Gray {
+			for q := Northwest; q <= Southeast; q++ {
+				queue = append(queue, n.Child(q))
+			}
+		}
+	}
+	return nil
+}
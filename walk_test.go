@@ -0,0 +1,109 @@
+package rquad
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWalkOrders(t *testing.T) {
+	for _, impl := range []Implementation{Basic, CardinalNeighbour} {
+		q := loadTree(t, "./testdata/labyrinth2.32x32.png", WithImplementation(impl))
+		all := allNodes(q.Root())
+
+		var pre []Node
+		check(t, Walk(q.Root(), PreOrder, func(n Node) error {
+			pre = append(pre, n)
+			return nil
+		}))
+		if len(pre) != len(all) {
+			t.Fatalf("pre-order: visited %d nodes, want %d", len(pre), len(all))
+		}
+		for i := range pre {
+			if pre[i] != all[i] {
+				t.Fatalf("pre-order: node %d is %v, want %v", i, pre[i].Bounds(), all[i].Bounds())
+			}
+		}
+
+		visited := make(map[Node]bool)
+		check(t, Walk(q.Root(), PostOrder, func(n Node) error {
+			if n.Color() == Gray {
+				for q := Northwest; q <= Southeast; q++ {
+					if !visited[n.Child(q)] {
+						t.Fatalf("post-order: node %v visited before its children", n.Bounds())
+					}
+				}
+			}
+			visited[n] = true
+			return nil
+		}))
+		if len(visited) != len(all) {
+			t.Fatalf("post-order: visited %d nodes, want %d", len(visited), len(all))
+		}
+
+		count, depth := 0, 0
+		check(t, Walk(q.Root(), BreadthFirst, func(n Node) error {
+			if Depth(n) < depth {
+				t.Fatalf("breadth-first: node %v of depth %d visited after depth %d", n.Bounds(), Depth(n), depth)
+			}
+			depth = Depth(n)
+			count++
+			return nil
+		}))
+		if count != len(all) {
+			t.Fatalf("breadth-first: visited %d nodes, want %d", count, len(all))
+		}
+	}
+}
+
+func TestWalkSkipStop(t *testing.T) {
+	q := loadTree(t, "./testdata/labyrinth2.32x32.png")
+	var want int
+	for level := 0; level <= 2; level++ {
+		check(t, ForEachNodeAtLevel(q, level, func(Node) { want++ }))
+	}
+
+	for _, order := range []WalkOrder{PreOrder, BreadthFirst} {
+		count := 0
+		err := Walk(q.Root(), order, func(n Node) error {
+			count++
+			if Depth(n) > 2 {
+				t.Fatalf("order %d: node %v visited in a skipped subtree", order, n.Bounds())
+			}
+			if Depth(n) == 2 {
+				return SkipSubtree
+			}
+			return nil
+		})
+		check(t, err)
+		if count != want {
+			t.Errorf("order %d: visited %d nodes, want %d", order, count, want)
+		}
+	}
+
+	errTest := errors.New("test")
+	for _, order := range []WalkOrder{PreOrder, PostOrder, BreadthFirst} {
+		count := 0
+		err := Walk(q.Root(), order, func(n Node) error {
+			count++
+			if count == 5 {
+				return StopWalk
+			}
+			return nil
+		})
+		if err != nil || count != 5 {
+			t.Errorf("order %d: got %v after %d nodes, want nil after 5", order, err, count)
+		}
+
+		count = 0
+		err = Walk(q.Root(), order, func(n Node) error {
+			count++
+			if count == 3 {
+				return errTest
+			}
+			return nil
+		})
+		if err != errTest || count != 3 {
+			t.Errorf("order %d: got %v after %d nodes, want %v after 3", order, err, count, errTest)
+		}
+	}
+}